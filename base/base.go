@@ -74,6 +74,20 @@ func (bt *BasicTypes) GetChainDecimal(chainName string) (int, error) {
 	return -1, errors.New("do not find this chain decimal")
 }
 
+func (bt *BasicTypes) GetChainSymbol(chainName string) (string, error) {
+	if len(bt.Registry) == 0 {
+		return "", fmt.Errorf("do not set base type registry")
+	}
+	for _, reg := range bt.Registry {
+		if strings.ToLower(reg.Network) == strings.ToLower(chainName) {
+			if len(reg.Symbols) != 0 {
+				return reg.Symbols[0], nil
+			}
+		}
+	}
+	return "", errors.New("do not find this chain symbol")
+}
+
 var defaultPrefix = []byte{0x2a}
 
 /*
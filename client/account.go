@@ -3,12 +3,14 @@ package client
 import (
 	"fmt"
 
+	"github.com/DataHighway-DHX/substrate-go/models"
+	"github.com/DataHighway-DHX/substrate-go/uint128"
 	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
 	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
 )
 
 func (c *Client) GetAccountInfo(acc signature.KeyringPair) (*types.AccountInfo, error) {
-	key, err := types.CreateStorageKey(c.Meta, "System", "Account", acc.PublicKey, nil)
+	key, err := types.CreateStorageKey(c.metadata(), "System", "Account", acc.PublicKey, nil)
 	if err != nil {
 		return nil, fmt.Errorf("can't create storage key %v", err)
 	}
@@ -19,3 +21,75 @@ func (c *Client) GetAccountInfo(acc signature.KeyringPair) (*types.AccountInfo,
 	}
 	return &accountInfo, nil
 }
+
+/*
+Read System.Account of accountId at latest state, decoded against the metadata so that the
+layout of the runtime (with or without sufficients, frozen instead of misc/fee frozen) is followed.
+An account that does not exist has a zero AccountInfo.
+*/
+func (c *Client) GetAccount(accountId []byte) (*models.AccountInfo, error) {
+	return c.accountAt(accountId, nil)
+}
+
+// accountAt returns System.Account at blockHash, or at the best block when blockHash is nil
+func (c *Client) accountAt(accountId []byte, blockHash *types.Hash) (*models.AccountInfo, error) {
+	meta := c.metadata()
+	key, err := types.CreateStorageKey(meta, "System", "Account", accountId, nil)
+	if err != nil {
+		return nil, fmt.Errorf("can't create storage key %v", err)
+	}
+	var raw *types.StorageDataRaw
+	if blockHash == nil {
+		raw, err = c.API.RPC.State.GetStorageRawLatest(key)
+	} else {
+		raw, err = c.API.RPC.State.GetStorageRaw(key, *blockHash)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't get storage for account %v", err)
+	}
+	if raw == nil || len(*raw) == 0 {
+		return &models.AccountInfo{}, nil
+	}
+	return decodeAccountInfo(meta, *raw)
+}
+
+func decodeAccountInfo(m *types.Metadata, raw []byte) (*models.AccountInfo, error) {
+	v, err := decodeStorageValue(m, "System", "Account", raw)
+	if err != nil {
+		return nil, err
+	}
+	fields, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected System.Account value %T", v)
+	}
+	data, _ := fields["data"].(map[string]interface{})
+	ai := &models.AccountInfo{
+		Nonce:       uint32(toBigInt(fields["nonce"]).Uint64()),
+		Consumers:   uint32(toBigInt(fields["consumers"]).Uint64()),
+		Providers:   uint32(toBigInt(fields["providers"]).Uint64()),
+		Sufficients: uint32(toBigInt(fields["sufficients"]).Uint64()),
+	}
+	balances := map[string]*uint128.Uint128{
+		"free": &ai.Free, "reserved": &ai.Reserved, "misc_frozen": &ai.MiscFrozen, "fee_frozen": &ai.FeeFrozen,
+	}
+	for name, dst := range balances {
+		if *dst, err = toUint128(data[name]); err != nil {
+			return nil, fmt.Errorf("invalid %s balance: %v", name, err)
+		}
+	}
+	if frozen, ok := data["frozen"]; ok {
+		// a single frozen balance since the fungible migration of pallet-balances
+		if ai.MiscFrozen, err = toUint128(frozen); err != nil {
+			return nil, fmt.Errorf("invalid frozen balance: %v", err)
+		}
+		ai.FeeFrozen = ai.MiscFrozen
+	}
+	return ai, nil
+}
+
+func toUint128(v interface{}) (uint128.Uint128, error) {
+	if v == nil {
+		return uint128.Zero, nil
+	}
+	return uint128.FromBigChecked(toBigInt(v))
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/DataHighway-DHX/substrate-go/ss58"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+var ErrNetworkMismatch = errors.New("address belongs to another network")
+
+// SS58 formats an account id in the address format of the connected chain
+func (c *Client) SS58(accountId []byte) (string, error) {
+	return ss58.EncodeWithNetwork(accountId, uint16(c.NetId))
+}
+
+/*
+Format a MultiAddress as it should be shown to users, returns the variant name and the address.
+Id and Address32 are SS58 encoded, Index is the decimal account index, Raw and Address20 are hex
+*/
+func FormatMultiAddress(m types.MultiAddress, network uint16) (kind, address string, err error) {
+	switch {
+	case m.IsID:
+		address, err = ss58.EncodeWithNetwork(m.AsID[:], network)
+		return "Id", address, err
+	case m.IsIndex:
+		return "Index", fmt.Sprintf("%d", m.AsIndex), nil
+	case m.IsRaw:
+		return "Raw", fmt.Sprintf("%#x", m.AsRaw), nil
+	case m.IsAddress32:
+		address, err = ss58.EncodeWithNetwork(m.AsAddress32[:], network)
+		return "Address32", address, err
+	case m.IsAddress20:
+		return "Address20", fmt.Sprintf("%#x", m.AsAddress20), nil
+	}
+	return "", "", fmt.Errorf("empty multi address")
+}
+
+// multiAddressFromValue converts a decoded MultiAddress (or plain AccountId) back to its typed form
+func multiAddressFromValue(v interface{}) (types.MultiAddress, bool) {
+	var m types.MultiAddress
+	switch a := v.(type) {
+	case HexBytes:
+		if len(a) != 32 {
+			return m, false
+		}
+		return types.NewMultiAddressFromAccountID(a), true
+	case *Variant:
+		switch a.Name {
+		case "Id":
+			return multiAddressFromValue(a.Value)
+		case "Index":
+			n := toBigInt(a.Value)
+			m.IsIndex = true
+			m.AsIndex = types.AccountIndex(n.Uint64())
+			return m, true
+		case "Raw":
+			b, ok := a.Value.(HexBytes)
+			m.IsRaw = true
+			m.AsRaw = b
+			return m, ok
+		case "Address32":
+			b, ok := a.Value.(HexBytes)
+			if !ok || len(b) != 32 {
+				return m, false
+			}
+			m.IsAddress32 = true
+			copy(m.AsAddress32[:], b)
+			return m, true
+		case "Address20":
+			b, ok := a.Value.(HexBytes)
+			if !ok || len(b) != 20 {
+				return m, false
+			}
+			m.IsAddress20 = true
+			copy(m.AsAddress20[:], b)
+			return m, true
+		}
+	}
+	return m, false
+}
+
+/*
+Parse an account given as an SS58 address or a 0x hex account id into a MultiAddress.
+SS58 addresses must pass checksum verification and carry the network prefix of the client,
+anyNetwork accepts addresses of other networks.
+*/
+func (c *Client) ParseAddress(address string, anyNetwork bool) (types.MultiAddress, error) {
+	address = strings.TrimSpace(address)
+	if strings.HasPrefix(address, "0x") {
+		b, err := types.HexDecodeString(address)
+		if err != nil {
+			return types.MultiAddress{}, fmt.Errorf("invalid hex account id %s: %v", address, err)
+		}
+		if len(b) != 32 {
+			return types.MultiAddress{}, fmt.Errorf("invalid hex account id %s: want 32 bytes, got %d", address, len(b))
+		}
+		return types.NewMultiAddressFromAccountID(b), nil
+	}
+	pub, network, err := ss58.DecodeWithNetwork(address)
+	if err != nil {
+		return types.MultiAddress{}, fmt.Errorf("invalid address %s: %v", address, err)
+	}
+	if !anyNetwork && network != uint16(c.NetId) {
+		return types.MultiAddress{}, fmt.Errorf("%w: %s has network prefix %d, client network is %d",
+			ErrNetworkMismatch, address, network, c.NetId)
+	}
+	return types.NewMultiAddressFromAccountID(pub), nil
+}
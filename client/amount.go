@@ -0,0 +1,103 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/DataHighway-DHX/substrate-go/models"
+	"github.com/shopspring/decimal"
+)
+
+// FormatAmount formats a planck amount as a decimal string, e.g. 1500000000000000000 with 18 decimals is 1.5
+func FormatAmount(raw *big.Int, decimals int) string {
+	if raw == nil {
+		raw = new(big.Int)
+	}
+	return decimal.NewFromBigInt(raw, -int32(decimals)).String()
+}
+
+/*
+Parse a human readable amount such as "1.5", "1.5 DHX" or "0.000001" into planck.
+When the amount carries a symbol it must match symbol, case insensitive.
+*/
+func ParseAmount(s string, decimals int, symbol string) (*big.Int, error) {
+	fields := strings.Fields(s)
+	switch len(fields) {
+	case 1:
+	case 2:
+		if symbol == "" || !strings.EqualFold(fields[1], symbol) {
+			return nil, fmt.Errorf("unexpected symbol %s, want %s", fields[1], symbol)
+		}
+	default:
+		return nil, fmt.Errorf("invalid amount %q", s)
+	}
+	d, err := decimal.NewFromString(fields[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount %q: %v", s, err)
+	}
+	if d.IsNegative() {
+		return nil, errors.New("amount must not be negative")
+	}
+	planck := d.Shift(int32(decimals))
+	if !planck.Equal(planck.Truncate(0)) {
+		return nil, fmt.Errorf("amount %s has more than %d decimals", fields[0], decimals)
+	}
+	return planck.BigInt(), nil
+}
+
+// NewAmount wraps a planck amount with the decimals and symbol of the connected chain
+func (c *Client) NewAmount(raw *big.Int) *models.Amount {
+	if raw == nil {
+		raw = new(big.Int)
+	}
+	return &models.Amount{
+		Raw:       raw.String(),
+		Decimals:  c.Decimals,
+		Symbol:    c.Symbol,
+		Formatted: FormatAmount(raw, c.Decimals),
+	}
+}
+
+// ParseAmount parses an amount such as "1.5 DHX" into planck using the decimals of the connected chain
+func (c *Client) ParseAmount(s string) (*big.Int, error) {
+	return ParseAmount(s, c.Decimals, c.Symbol)
+}
+
+/*
+Load token decimals and symbol from system_properties, falling back to the ss58 registry.
+Chains with several tokens report arrays, the first entry is the native token.
+*/
+func (c *Client) loadTokenInfo() {
+	var props map[string]interface{}
+	err := c.API.Client.Call(&props, "system_properties")
+	if err == nil {
+		if d, ok := firstProperty(props["tokenDecimals"]).(float64); ok {
+			c.Decimals = int(d)
+		}
+		if s, ok := firstProperty(props["tokenSymbol"]).(string); ok {
+			c.Symbol = s
+		}
+	}
+	if c.Decimals == 0 {
+		if d, err := c.BasicType.GetChainDecimal(string(c.RuntimeVersion.SpecName)); err == nil {
+			c.Decimals = d
+		}
+	}
+	if c.Symbol == "" {
+		if s, err := c.BasicType.GetChainSymbol(string(c.RuntimeVersion.SpecName)); err == nil {
+			c.Symbol = s
+		}
+	}
+}
+
+func firstProperty(v interface{}) interface{} {
+	if list, ok := v.([]interface{}); ok {
+		if len(list) == 0 {
+			return nil
+		}
+		return list[0]
+	}
+	return v
+}
@@ -0,0 +1,246 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/DataHighway-DHX/substrate-go/uint128"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+var (
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	// the sender would be left below the existential deposit and its account removed
+	ErrReapsSender = errors.New("transfer would reap the sender")
+	// the recipient does not exist and would be created with less than the existential deposit
+	ErrBelowExistentialDeposit = errors.New("transfer would create the recipient below the existential deposit")
+)
+
+// BalanceError reports a failed balance check, Err is one of the errors above
+type BalanceError struct {
+	Err       error
+	Account   string // hex account id
+	Available uint128.Uint128
+	Required  uint128.Uint128
+}
+
+func (e *BalanceError) Error() string {
+	return fmt.Sprintf("%v: account %s has %s, requires %s", e.Err, e.Account, e.Available, e.Required)
+}
+
+func (e *BalanceError) Unwrap() error {
+	return e.Err
+}
+
+// TransferCheck is what the balance checks of a signed transfer found
+type TransferCheck struct {
+	Transferable       uint128.Uint128 // free balance of the account the funds move from that is not frozen
+	Amount             uint128.Uint128 // transferred, for TransferAll what would remain after the fee
+	Fee                uint128.Uint128 // estimated partial fee plus tip
+	ExistentialDeposit uint128.Uint128
+	// the transfer would reap the sender, only a warning for TransferAllowDeath and TransferAll
+	ReapsSender *BalanceError
+}
+
+// ExistentialDeposit reads the Balances.ExistentialDeposit constant of the runtime
+func (c *Client) ExistentialDeposit() (uint128.Uint128, error) {
+	v, err := decodeConstant(c.metadata(), "Balances", "ExistentialDeposit")
+	if err != nil {
+		return uint128.Zero, err
+	}
+	return uint128.FromBigChecked(toBigInt(v))
+}
+
+/*
+Check a signed transfer against the balances before it is submitted. The transferable balance of the
+sender must cover the amount plus the estimated fee, and a recipient that does not exist must receive
+at least the existential deposit. A transfer that leaves the sender below the existential deposit
+fails for TransferKeepAlive and is reported in ReapsSender otherwise. ForceTransfer moves funds of
+another account and is not checked. Failed checks are returned as *BalanceError.
+*/
+func (c *Client) CheckTransfer(ext types.Extrinsic, t Transfer) (*TransferCheck, error) {
+	return c.checkTransfers(ext, nil, []Transfer{t})
+}
+
+/*
+CheckBatch runs the checks of CheckTransfer for a signed batch of transfers, the amounts are summed
+and the fee is the one of the whole batch. The sender must not be reaped if any transfer is a
+TransferKeepAlive, and a TransferAll sweeps what the other transfers and the fee leave.
+*/
+func (c *Client) CheckBatch(ext types.Extrinsic, transfers []Transfer) (*TransferCheck, error) {
+	return c.checkTransfers(ext, nil, transfers)
+}
+
+/*
+CheckProxyTransfer runs the checks of CheckTransfer for a transfer of the funds of real signed by
+its proxy, see SignProxyTransfer. The amount is checked against real and the fee against the proxy,
+which pays it.
+*/
+func (c *Client) CheckProxyTransfer(ext types.Extrinsic, real []byte, t Transfer) (*TransferCheck, error) {
+	return c.checkTransfers(ext, real, []Transfer{t})
+}
+
+// maxBalance is reported as required when the sum of a check overflows
+var maxBalance = uint128.New(math.MaxUint64, math.MaxUint64)
+
+// addBalance adds two balances, ok is false when the sum overflows
+func addBalance(a, b uint128.Uint128) (sum uint128.Uint128, ok bool) {
+	sum = a.Add(b)
+	return sum, sum.Cmp(a) >= 0
+}
+
+// checkTransfers checks transfers from origin, the signer when origin is nil
+func (c *Client) checkTransfers(ext types.Extrinsic, origin []byte, transfers []Transfer) (*TransferCheck, error) {
+	sender := signerAccountId(ext)
+	if sender == nil {
+		return nil, fmt.Errorf("transfer is not signed by an account id")
+	}
+	if origin == nil {
+		origin = sender
+	}
+	ed, err := c.ExistentialDeposit()
+	if err != nil {
+		return nil, err
+	}
+	check := &TransferCheck{ExistentialDeposit: ed}
+	if len(transfers) == 1 && transfers[0].Kind == ForceTransfer {
+		check.Amount = transfers[0].Value
+		return check, nil
+	}
+	fee, err := c.QueryFee(ext)
+	if err != nil {
+		return nil, err
+	}
+	check.Fee, err = uint128.FromString(fee.Total.Raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fee %v", err)
+	}
+	ai, err := c.GetAccount(origin)
+	if err != nil {
+		return nil, err
+	}
+	check.Transferable = ai.Transferable()
+	originHex := types.HexEncodeToString(origin)
+	insufficient := func(required uint128.Uint128) error {
+		return &BalanceError{Err: ErrInsufficientBalance, Account: originHex, Available: check.Transferable, Required: required}
+	}
+	// the signer pays the fee, a proxy from its own funds
+	spent := check.Fee
+	if !bytes.Equal(origin, sender) {
+		si, err := c.GetAccount(sender)
+		if err != nil {
+			return nil, err
+		}
+		if si.Transferable().Cmp(check.Fee) < 0 {
+			return nil, &BalanceError{Err: ErrInsufficientBalance, Account: types.HexEncodeToString(sender),
+				Available: si.Transferable(), Required: check.Fee}
+		}
+		spent = uint128.Zero
+	}
+
+	var sweep, keepAlive bool
+	for _, t := range transfers {
+		switch t.Kind {
+		case ForceTransfer:
+			continue
+		case TransferAll:
+			// TransferAll with KeepAlive leaves the existential deposit behind by itself
+			sweep = true
+			keepAlive = keepAlive || t.KeepAlive
+			continue
+		}
+		var ok bool
+		check.Amount, ok = addBalance(check.Amount, t.Value)
+		if !ok {
+			return nil, insufficient(maxBalance)
+		}
+	}
+	required, ok := addBalance(check.Amount, spent)
+	if !ok {
+		return nil, insufficient(maxBalance)
+	}
+	if check.Transferable.Cmp(required) < 0 {
+		return nil, insufficient(required)
+	}
+	if sweep {
+		check.Amount = check.Transferable.Sub(spent)
+		required = check.Transferable
+	}
+
+	if ai.Free.Sub(required).Cmp(ed) < 0 && !keepAlive {
+		reapsAt, ok := addBalance(required, ed)
+		if !ok {
+			reapsAt = maxBalance
+		}
+		reaped := &BalanceError{Err: ErrReapsSender, Account: originHex, Available: ai.Free, Required: reapsAt}
+		for _, t := range transfers {
+			if t.Kind == TransferKeepAlive {
+				return nil, reaped
+			}
+		}
+		check.ReapsSender = reaped
+	}
+
+	for _, t := range transfers {
+		if t.Kind == ForceTransfer {
+			continue
+		}
+		recipient, err := c.transferRecipient(t)
+		if err != nil {
+			return nil, err
+		}
+		if recipient == nil {
+			continue
+		}
+		ri, err := c.GetAccount(recipient)
+		if err != nil {
+			return nil, err
+		}
+		amount := t.Value
+		if t.Kind == TransferAll {
+			amount = check.Amount
+		}
+		if ri.Providers == 0 && ri.Free.IsZero() && amount.Cmp(ed) < 0 {
+			return nil, &BalanceError{Err: ErrBelowExistentialDeposit, Account: types.HexEncodeToString(recipient),
+				Available: amount, Required: ed}
+		}
+	}
+	return check, nil
+}
+
+// transferRecipient returns the account id of the destination, nil for addresses that are not one
+func (c *Client) transferRecipient(t Transfer) ([]byte, error) {
+	to := t.DestAddress
+	if to == nil {
+		addr, err := c.ParseAddress(t.Dest, t.AllowOtherNetwork)
+		if err != nil {
+			return nil, fmt.Errorf("can't get reciever multi address %w", err)
+		}
+		to = &addr
+	}
+	if !to.IsID {
+		return nil, nil
+	}
+	return to.AsID[:], nil
+}
+
+/*
+checkBalances runs the checks of CheckTransfer on the transfers of ext from origin, the signer when
+nil, when CheckBalances is set. A failing transfer hands its nonce back.
+*/
+func (c *Client) checkBalances(ext types.Extrinsic, origin []byte, transfers ...Transfer) error {
+	if !c.CheckBalances {
+		return nil
+	}
+	check, err := c.checkTransfers(ext, origin, transfers)
+	if err != nil {
+		c.submitFailed(ext, err)
+		return err
+	}
+	if check.ReapsSender != nil && c.OnWarning != nil {
+		c.OnWarning(check.ReapsSender)
+	}
+	return nil
+}
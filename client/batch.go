@@ -0,0 +1,307 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/DataHighway-DHX/substrate-go/signer"
+	"github.com/DataHighway-DHX/substrate-go/uint128"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+// BatchMode selects the Utility call transfers are batched with
+type BatchMode int
+
+const (
+	// Utility.batch_all, all transfers of a batch succeed or none
+	BatchAll BatchMode = iota
+	// Utility.batch, stops at the first failing transfer
+	Batch
+	// Utility.force_batch, continues past failing transfers
+	ForceBatch
+)
+
+func (m BatchMode) callName() (string, error) {
+	switch m {
+	case BatchAll:
+		return "Utility.batch_all", nil
+	case Batch:
+		return "Utility.batch", nil
+	case ForceBatch:
+		return "Utility.force_batch", nil
+	}
+	return "", fmt.Errorf("unknown batch mode %d", m)
+}
+
+// BatchChunk is one batch call and the indexes of the transfers it carries
+type BatchChunk struct {
+	Call  types.Call
+	Items []int
+}
+
+type BatchItemResult struct {
+	Transfer int    `json:"transfer"` // index into the transfers
+	Status   string `json:"status"`   // success, fail or skipped
+	Error    string `json:"error,omitempty"`
+}
+
+type BatchResult struct {
+	*TxResult
+	Items []BatchItemResult `json:"items"`
+}
+
+/*
+Pack transfers into as few batch calls as the block limits allow. The weight of each kind of
+transfer is measured with payment_queryInfo on a batch signed by accountId with its crypto type, and a batch is split
+before its weight would exceed the normal class max_extrinsic of System.BlockWeights or its
+length the normal class limit of System.BlockLength.
+*/
+func (c *Client) NewBatchCalls(transfers []Transfer, mode BatchMode, accountId []byte, crypto signer.CryptoType) ([]BatchChunk, error) {
+	name, err := mode.callName()
+	if err != nil {
+		return nil, err
+	}
+	meta, rv := c.runtime()
+	if !hasCall(meta, name) {
+		return nil, fmt.Errorf("%w: %s (spec %s v%d)", ErrCallNotFound, name,
+			rv.SpecName, rv.SpecVersion)
+	}
+	calls := make([]types.Call, len(transfers))
+	for i, t := range transfers {
+		calls[i], err = c.NewTransferCall(t)
+		if err != nil {
+			return nil, fmt.Errorf("transfer %d: %w", i, err)
+		}
+	}
+	maxWeight, maxLength, err := c.batchLimits()
+	if err != nil {
+		return nil, err
+	}
+
+	batch := func(calls []types.Call) (types.Call, error) {
+		list := make([]interface{}, len(calls))
+		for i := range calls {
+			list[i] = calls[i]
+		}
+		return NewCall(meta, name, list)
+	}
+	measure := func(calls []types.Call) (weight uint64, length int, err error) {
+		ca, err := batch(calls)
+		if err != nil {
+			return 0, 0, err
+		}
+		ext, err := c.dummySigned(ca, accountId, crypto, uint128.Zero)
+		if err != nil {
+			return 0, 0, err
+		}
+		fee, err := c.QueryFee(ext)
+		if err != nil {
+			return 0, 0, err
+		}
+		return fee.Weight, fee.Length, nil
+	}
+
+	// weight of the empty batch, then of each kind of transfer on top of it
+	baseWeight, overhead, err := measure(nil)
+	if err != nil {
+		return nil, err
+	}
+	itemWeight := make(map[types.CallIndex]uint64)
+	for _, ca := range calls {
+		if _, ok := itemWeight[ca.CallIndex]; ok {
+			continue
+		}
+		one, _, err := measure([]types.Call{ca})
+		if err != nil {
+			return nil, err
+		}
+		if one > baseWeight {
+			itemWeight[ca.CallIndex] = one - baseWeight
+		} else {
+			itemWeight[ca.CallIndex] = 0
+		}
+	}
+	// room for the compact length prefixes to grow
+	overhead += 8
+
+	var chunks []BatchChunk
+	var items []int
+	weight, length := baseWeight, overhead
+	flush := func() error {
+		if len(items) == 0 {
+			return nil
+		}
+		selected := make([]types.Call, len(items))
+		for i, idx := range items {
+			selected[i] = calls[idx]
+		}
+		ca, err := batch(selected)
+		if err != nil {
+			return err
+		}
+		chunks = append(chunks, BatchChunk{Call: ca, Items: items})
+		items, weight, length = nil, baseWeight, overhead
+		return nil
+	}
+	for i, ca := range calls {
+		encoded, err := types.Encode(ca)
+		if err != nil {
+			return nil, err
+		}
+		w, l := itemWeight[ca.CallIndex], len(encoded)
+		if baseWeight+w > maxWeight || overhead+l > maxLength {
+			return nil, fmt.Errorf("transfer %d alone exceeds the block limits", i)
+		}
+		if weight+w > maxWeight || length+l > maxLength {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+		items = append(items, i)
+		weight += w
+		length += l
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return chunks, nil
+}
+
+// batchLimits returns the normal class extrinsic weight and block length limits
+func (c *Client) batchLimits() (maxWeight uint64, maxLength int, err error) {
+	meta := c.metadata()
+	bw, err := decodeConstant(meta, "System", "BlockWeights")
+	if err != nil {
+		return 0, 0, err
+	}
+	weights, _ := bw.(map[string]interface{})
+	perClass, _ := weights["per_class"].(map[string]interface{})
+	normal, _ := perClass["normal"].(map[string]interface{})
+	for _, limit := range []interface{}{normal["max_extrinsic"], normal["max_total"], weights["max_block"]} {
+		if limit != nil {
+			maxWeight = weightRefTime(limit)
+			break
+		}
+	}
+	if maxWeight == 0 {
+		return 0, 0, fmt.Errorf("can't read the block weight limit from System.BlockWeights")
+	}
+
+	bl, err := decodeConstant(meta, "System", "BlockLength")
+	if err != nil {
+		return 0, 0, err
+	}
+	length, _ := bl.(map[string]interface{})
+	max, _ := length["max"].(map[string]interface{})
+	maxLength = int(toBigInt(max["normal"]).Int64())
+	if maxLength == 0 {
+		return 0, 0, fmt.Errorf("can't read the block length limit from System.BlockLength")
+	}
+	return maxWeight, maxLength, nil
+}
+
+// weightRefTime reads a decoded one dimensional weight or the ref_time of a two dimensional one
+func weightRefTime(v interface{}) uint64 {
+	if m, ok := v.(map[string]interface{}); ok {
+		v = m["ref_time"]
+	}
+	return toBigInt(v).Uint64()
+}
+
+/*
+Send transfers from the sender in as few batch extrinsics as the block limits allow. Batches are
+submitted one after the other, each waited for until it is in a block or finalized, and the
+outcome of every transfer is reported. With CheckBalances every batch is checked with CheckBatch
+before it is sent. On error the results of the batches sent so far are returned.
+*/
+func (c *Client) BatchTransfer(ctx context.Context, from signer.Signer, transfers []Transfer, mode BatchMode,
+	tip uint128.Uint128, until WaitUntil) ([]*BatchResult, error) {
+	err := c.checkRuntimeVersion()
+	if err != nil {
+		return nil, err
+	}
+	chunks, err := c.NewBatchCalls(transfers, mode, signer.AccountId(from), from.CryptoType())
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*BatchResult
+	for _, chunk := range chunks {
+		ext, err := c.SignCall(from, chunk.Call, tip)
+		if err != nil {
+			return results, err
+		}
+		batched := make([]Transfer, len(chunk.Items))
+		for i, idx := range chunk.Items {
+			batched[i] = transfers[idx]
+		}
+		err = c.vetTransfers(ext, nil, batched...)
+		if err != nil {
+			return results, err
+		}
+		res, err := c.SubmitAndWait(ctx, ext, until)
+		if err != nil {
+			return results, err
+		}
+		meta, err := c.metadataAt(res.BlockHash)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, &BatchResult{TxResult: res, Items: BatchOutcome(meta, res, chunk.Items)})
+	}
+	return results, nil
+}
+
+/*
+Outcome of each item of an included batch extrinsic, items are the transfer indexes of the batch.
+Runtimes that emit ItemCompleted/ItemFailed report every item, older ones only BatchInterrupted
+and BatchCompleted. A failed batch_all extrinsic fails all of its items.
+*/
+func BatchOutcome(m *types.Metadata, res *TxResult, items []int) []BatchItemResult {
+	out := make([]BatchItemResult, len(items))
+	for i, idx := range items {
+		out[i] = BatchItemResult{Transfer: idx, Status: "skipped"}
+	}
+	if !res.Success {
+		for i := range out {
+			out[i].Status, out[i].Error = "fail", res.Error
+		}
+		return out
+	}
+	next, perItem := 0, false
+	for _, e := range res.Events {
+		if e.Pallet != "Utility" {
+			continue
+		}
+		switch e.Name {
+		case "ItemCompleted":
+			perItem = true
+			if next < len(out) {
+				out[next].Status = "success"
+			}
+			next++
+		case "ItemFailed":
+			perItem = true
+			if next < len(out) {
+				v, _ := e.Field("error", 0)
+				out[next].Status, out[next].Error = "fail", dispatchErrorString(m, v)
+			}
+			next++
+		case "BatchInterrupted":
+			index, _ := e.Field("index", 0)
+			v, _ := e.Field("error", 1)
+			failed := int(toBigInt(index).Int64())
+			for i := 0; i < failed && i < len(out) && !perItem; i++ {
+				out[i].Status = "success"
+			}
+			if failed < len(out) {
+				out[failed].Status, out[failed].Error = "fail", dispatchErrorString(m, v)
+			}
+		case "BatchCompleted":
+			for i := 0; i < len(out) && !perItem; i++ {
+				out[i].Status = "success"
+			}
+		}
+	}
+	return out
+}
@@ -48,60 +48,100 @@ func (c *Client) GetBlockByHash(blockHash types.Hash) (*models.BlockResponse, er
 	blockResp.ParentHash = block.Block.Header.ParentHash.Hex()
 	blockResp.BlockHash = blockHash.Hex()
 
-	ts, err := c.getBlockTimestamp(block.Block.Extrinsics)
+	ts, ok, err := c.getBlockTimestamp(blockHash, block.Block.Extrinsics)
 	if err != nil {
 		return nil, fmt.Errorf("unable to get block timestamp: %v", err)
 	}
-	blockResp.Timestamp = ts.Unix()
+	if ok {
+		unix := ts.Unix()
+		blockResp.Timestamp = &unix
+	}
 
 	blockResp.Extrinsic, err = c.parseExtrinsic(blockHash, block.Block.Header.ParentHash, block.Block.Extrinsics)
 	if err != nil {
 		return nil, err
 	}
 
+	if c.TxIndex != nil {
+		err = c.indexFinalizedBlock(blockHash, number, block.Block.Extrinsics)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return blockResp, nil
 }
 
 func (c *Client) parseExtrinsic(blockHash, parentHash types.Hash, extrinsics []types.Extrinsic) ([]*models.ExtrinsicResponse, error) {
-	var (
-		eventKey types.StorageKey
-		err      error
-	)
+	var err error
 	exts := []*models.ExtrinsicResponse{}
 	if len(extrinsics) == 0 {
 		return exts, nil
 	}
 
-	c.Meta, err = c.API.RPC.State.GetMetadataLatest()
+	meta, err := c.metadataAt(blockHash)
 	if err != nil {
-		return nil, fmt.Errorf("cannot fetch latest metadata %v", err)
-	}
-
-	eventKey, err = types.CreateStorageKey(c.Meta, "System", "Events")
-	if err != nil {
-		return nil, fmt.Errorf("unable to create storage key:%v", err)
-	}
-
-	raw, err := c.API.RPC.State.GetStorageRaw(eventKey, blockHash)
-	if err != nil {
-		return nil, fmt.Errorf("unable to query storage: %v", err)
+		return nil, err
 	}
 
-	var events types.EventRecords
-	err = (*types.EventRecordsRaw)(raw).DecodeEventRecords(c.Meta, &events)
+	events, err := c.GetBlockEvents(blockHash)
 	if err != nil {
-		return nil, fmt.Errorf("unable to decode event records: %v", err)
+		return nil, err
 	}
 
-	for _, tr := range events.Balances_Transfer {
-		if !(len(extrinsics) > int(tr.Phase.AsApplyExtrinsic)) {
-			return nil, fmt.Errorf("unable to access extrinsics by index: %d", tr.Phase.AsApplyExtrinsic)
+	transfers := make(map[int][]*transferCall)
+	decodeErrors := make(map[int]string)
+	for _, tr := range transferEvents(events) {
+		if !(len(extrinsics) > tr.index) {
+			return nil, fmt.Errorf("unable to access extrinsics by index: %d", tr.index)
+		}
+		currentExt := extrinsics[tr.index]
+		calls, ok := transfers[tr.index]
+		if !ok {
+			// the event is still reported, flagged so that it is not mistaken for a plain transfer
+			calls, err = extrinsicTransfers(meta, currentExt)
+			if err != nil {
+				decodeErrors[tr.index] = err.Error()
+			}
+			transfers[tr.index] = calls
+		}
+		callPath, destType, dest := "", "", ""
+		if tc := matchTransfer(calls, tr); tc != nil {
+			callPath = tc.path
+			if ma, ok := multiAddressFromValue(tc.dest); ok {
+				destType, dest, err = FormatMultiAddress(ma, uint16(c.NetId))
+				if err != nil {
+					return nil, fmt.Errorf("unable to format destination address: %v", err)
+				}
+			}
+		}
+		from, err := c.SS58(tr.from)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode from address: %v", err)
+		}
+		to, err := c.SS58(tr.to)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode to address: %v", err)
+		}
+		signer, signerPub := "", ""
+		if currentExt.IsSigned() {
+			_, signer, err = FormatMultiAddress(currentExt.Signature.Signer, uint16(c.NetId))
+			if err != nil {
+				return nil, fmt.Errorf("unable to format signer address: %v", err)
+			}
+			if pub := signerAccountId(currentExt); pub != nil {
+				signerPub = fmt.Sprintf("%#x", pub)
+			}
 		}
-		currentExt := extrinsics[tr.Phase.AsApplyExtrinsic]
 		fee, err := c.getPartialFee(currentExt, parentHash.Hex())
 		if err != nil {
 			return nil, fmt.Errorf("unable to get block timestamp: %v", err)
 		}
+		feeRaw, ok := new(big.Int).SetString(fee, 0)
+		if !ok {
+			return nil, fmt.Errorf("invalid partial fee: %s", fee)
+		}
+		feeValue := c.NewAmount(feeRaw)
 
 		td, err := txDataFromExtrinsic(currentExt)
 		if err != nil {
@@ -112,16 +152,26 @@ func (c *Client) parseExtrinsic(blockHash, parentHash types.Hash, extrinsics []t
 			&models.ExtrinsicResponse{
 				Type:            "transfer",
 				Status:          "success",
-				Amount:          tr.Value.String(),
-				FromAddress:     fmt.Sprintf("%#x", tr.From),
-				ToAddress:       fmt.Sprintf("%#x", tr.To),
-				EventIndex:      int(tr.Phase.AsApplyExtrinsic),
+				Amount:          tr.amount.String(),
+				AmountValue:     c.NewAmount(tr.amount),
+				FromAddress:     from,
+				FromPublicKey:   fmt.Sprintf("%#x", tr.from),
+				ToAddress:       to,
+				ToPublicKey:     fmt.Sprintf("%#x", tr.to),
+				DestType:        destType,
+				Dest:            dest,
+				EventIndex:      tr.index,
+				Signer:          signer,
+				SignerPublicKey: signerPub,
+				CallPath:        callPath,
+				DecodeError:     decodeErrors[tr.index],
 				Txid:            td.txid,
 				Fee:             fee,
+				FeeValue:        feeValue,
 				Era:             td.era,
 				Signature:       td.sig,
 				Nonce:           currentExt.Signature.Nonce.Int64(),
-				ExtrinsicIndex:  int(tr.Phase.AsApplyExtrinsic),
+				ExtrinsicIndex:  tr.index,
 				ExtrinsicLength: td.len,
 			})
 	}
@@ -129,6 +179,33 @@ func (c *Client) parseExtrinsic(blockHash, parentHash types.Hash, extrinsics []t
 	return exts, nil
 }
 
+// transferEvent is a Balances.Transfer event emitted while applying the extrinsic at index
+type transferEvent struct {
+	index    int
+	from, to []byte
+	amount   *big.Int
+}
+
+// transferEvents returns the Balances.Transfer events of extrinsics among the events of a block
+func transferEvents(events []*DecodedEvent) []*transferEvent {
+	var res []*transferEvent
+	for _, e := range events {
+		if !e.Is("Balances", "Transfer") || e.ExtrinsicIndex < 0 {
+			continue
+		}
+		from, _ := e.Field("from", 0)
+		to, _ := e.Field("to", 1)
+		amount, _ := e.Field("amount", 2)
+		res = append(res, &transferEvent{
+			index:  e.ExtrinsicIndex,
+			from:   accountIdFromValue(from),
+			to:     accountIdFromValue(to),
+			amount: toBigInt(amount),
+		})
+	}
+	return res
+}
+
 type txData struct {
 	txid, era, sig string
 	len            int
@@ -155,6 +232,26 @@ func txDataFromExtrinsic(ext types.Extrinsic) (td *txData, err error) {
 	return
 }
 
+func signerAccountId(ext types.Extrinsic) []byte {
+	if !ext.IsSigned() || !ext.Signature.Signer.IsID {
+		return nil
+	}
+	return ext.Signature.Signer.AsID[:]
+}
+
+// extrinsicTransfers decodes the call tree of an extrinsic against meta and returns the Balances transfers it dispatches
+func extrinsicTransfers(meta *types.Metadata, ext types.Extrinsic) ([]*transferCall, error) {
+	callBytes, err := types.Encode(ext.Method)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode call: %v", err)
+	}
+	call, err := decodeCall(meta, callBytes)
+	if err != nil {
+		return nil, err
+	}
+	return collectTransfers(call, signerAccountId(ext), ""), nil
+}
+
 func getTxId(ext types.Extrinsic) (string, error) {
 	extBytes, err := types.Encode(ext)
 	if err != nil {
@@ -231,27 +328,51 @@ func (c *Client) getPartialFee(ext types.Extrinsic, parentHash string) (string,
 	return fee, nil
 }
 
-func (c *Client) getBlockTimestamp(ext []types.Extrinsic) (*time.Time, error) {
-
-	// callIndex needed to find correct Extrinsic
-	callIndex, err := c.Meta.FindCallIndex("Timestamp.set")
+/*
+Timestamp of a block. The Timestamp.set inherent is decoded when present, otherwise
+Timestamp.Now is read from storage at the block. ok is false when the block has no timestamp,
+e.g. the genesis block or a runtime without the Timestamp pallet.
+*/
+func (c *Client) getBlockTimestamp(blockHash types.Hash, ext []types.Extrinsic) (ts time.Time, ok bool, err error) {
+	meta, err := c.metadataAt(blockHash)
 	if err != nil {
-		return nil, err
+		return ts, false, err
+	}
+	if !meta.ExistsModuleMetadata("Timestamp") {
+		return ts, false, nil
 	}
 
-	timestamp := new(big.Int)
-	for _, extrinsic := range ext {
-		if extrinsic.Method.CallIndex != callIndex {
-			continue
-		}
-		timeDecoder := scale.NewDecoder(bytes.NewReader(extrinsic.Method.Args))
-		timestamp, err = timeDecoder.DecodeUintCompact()
-		if err != nil {
-			return nil, err
+	// callIndex needed to find correct Extrinsic
+	callIndex, err := meta.FindCallIndex("Timestamp.set")
+	if err == nil {
+		for _, extrinsic := range ext {
+			if extrinsic.IsSigned() || extrinsic.Method.CallIndex != callIndex {
+				continue
+			}
+			timeDecoder := scale.NewDecoder(bytes.NewReader(extrinsic.Method.Args))
+			timestamp, err := timeDecoder.DecodeUintCompact()
+			if err != nil {
+				return ts, false, fmt.Errorf("unable to decode Timestamp.set: %v", err)
+			}
+			return unixMilli(timestamp.Int64()), true, nil
 		}
-		break
 	}
-	msec := timestamp.Int64()
-	time := time.Unix(msec/1e3, (msec%1e3)*1e6)
-	return &time, nil
+
+	key, err := types.CreateStorageKey(meta, "Timestamp", "Now")
+	if err != nil {
+		return ts, false, fmt.Errorf("unable to create storage key: %v", err)
+	}
+	var now types.U64
+	found, err := c.API.RPC.State.GetStorage(key, &now, blockHash)
+	if err != nil {
+		return ts, false, fmt.Errorf("unable to query Timestamp.Now: %v", err)
+	}
+	if !found || now == 0 {
+		return ts, false, nil
+	}
+	return unixMilli(int64(now)), true, nil
+}
+
+func unixMilli(msec int64) time.Time {
+	return time.Unix(msec/1e3, (msec%1e3)*1e6)
 }
@@ -0,0 +1,215 @@
+package client
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"golang.org/x/crypto/blake2b"
+)
+
+// transferCall is a Balances transfer found somewhere in the call tree of an extrinsic
+type transferCall struct {
+	path   string
+	origin []byte
+	dest   interface{}
+	amount *big.Int
+	used   bool
+}
+
+// CallTransfer is a Balances transfer dispatched somewhere in the call tree of a call
+type CallTransfer struct {
+	Path   string      // e.g. batch_all[2].proxy.transfer_keep_alive
+	Origin []byte      // account the funds are moved from, nil when it can't be derived
+	Dest   interface{} // decoded destination of the call
+	Amount *big.Int    // nil for transfer_all
+}
+
+/*
+Decode a call and return the Balances transfers it dispatches, signer is the account the call is
+signed by. The origin of nested calls follows Proxy.proxy, Multisig.as_multi, Utility.as_derivative
+and Sudo.sudo_as.
+*/
+func CallTransfers(m *types.Metadata, ca types.Call, signer []byte) ([]CallTransfer, error) {
+	callBytes, err := types.Encode(ca)
+	if err != nil {
+		return nil, fmt.Errorf("can't encode call %v", err)
+	}
+	call, err := decodeCall(m, callBytes)
+	if err != nil {
+		return nil, err
+	}
+	var res []CallTransfer
+	for _, tc := range collectTransfers(call, signer, "") {
+		res = append(res, CallTransfer{Path: tc.path, Origin: tc.origin, Dest: tc.dest, Amount: tc.amount})
+	}
+	return res, nil
+}
+
+var transferCalls = map[string]bool{
+	"transfer":             true,
+	"transfer_keep_alive":  true,
+	"transfer_allow_death": true,
+	"transfer_all":         true,
+	"force_transfer":       true,
+}
+
+/*
+Walk the call tree and collect every Balances transfer with the origin it is dispatched from.
+Paths look like batch_all[2].transfer_keep_alive or proxy.as_multi.transfer
+*/
+func collectTransfers(call *DecodedCall, origin []byte, path string) []*transferCall {
+	var out []*transferCall
+	if call.Pallet == "Balances" && transferCalls[call.Call] {
+		tc := &transferCall{path: path + call.Call, origin: origin}
+		tc.dest, _ = call.Arg("dest")
+		if v, ok := call.Arg("value"); ok {
+			tc.amount = toBigInt(v)
+		}
+		if call.Call == "force_transfer" {
+			src, _ := call.Arg("source")
+			tc.origin = accountIdFromValue(src)
+		}
+		return append(out, tc)
+	}
+
+	inner := innerOrigin(call, origin)
+	for _, a := range call.Args {
+		switch v := a.Value.(type) {
+		case *DecodedCall:
+			out = append(out, collectTransfers(v, inner, path+call.Call+".")...)
+		case []interface{}:
+			for i, item := range v {
+				if c, ok := item.(*DecodedCall); ok {
+					out = append(out, collectTransfers(c, inner, fmt.Sprintf("%s%s[%d].", path, call.Call, i))...)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// innerOrigin returns the origin nested calls of call are dispatched from, nil if unknown
+func innerOrigin(call *DecodedCall, origin []byte) []byte {
+	switch call.Pallet + "." + call.Call {
+	case "Proxy.proxy", "Proxy.proxy_announced":
+		r, _ := call.Arg("real")
+		return accountIdFromValue(r)
+	case "Multisig.as_multi", "Multisig.as_multi_threshold_1":
+		if origin == nil {
+			return nil
+		}
+		threshold := uint16(1)
+		if v, ok := call.Arg("threshold"); ok {
+			threshold = uint16(toBigInt(v).Uint64())
+		}
+		others, _ := call.Arg("other_signatories")
+		list, _ := others.([]interface{})
+		signatories := [][]byte{origin}
+		for _, o := range list {
+			acc := accountIdFromValue(o)
+			if acc == nil {
+				return nil
+			}
+			signatories = append(signatories, acc)
+		}
+		return MultisigAccountId(signatories, threshold)
+	case "Utility.as_derivative":
+		if origin == nil {
+			return nil
+		}
+		index, _ := call.Arg("index")
+		return derivativeAccountId(origin, uint16(toBigInt(index).Uint64()))
+	case "Sudo.sudo_as":
+		who, _ := call.Arg("who")
+		return accountIdFromValue(who)
+	}
+	return origin
+}
+
+// accountIdFromValue extracts a 32 byte account id from a decoded AccountId or MultiAddress
+func accountIdFromValue(v interface{}) []byte {
+	switch a := v.(type) {
+	case HexBytes:
+		if len(a) == 32 {
+			return a
+		}
+	case *Variant:
+		if a.Name == "Id" || a.Name == "Address32" {
+			return accountIdFromValue(a.Value)
+		}
+	}
+	return nil
+}
+
+func toBigInt(v interface{}) *big.Int {
+	switch n := v.(type) {
+	case *big.Int:
+		return n
+	case uint64:
+		return new(big.Int).SetUint64(n)
+	case int64:
+		return big.NewInt(n)
+	}
+	return new(big.Int)
+}
+
+var utilitySubPrefix = []byte("modlpy/utilisuba")
+
+// MultisigAccountId derives the multisig account id from its signatories and threshold, their order does not matter
+func MultisigAccountId(signatories [][]byte, threshold uint16) []byte {
+	sorted := make([][]byte, len(signatories))
+	copy(sorted, signatories)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i], sorted[j]) < 0
+	})
+
+	var buf bytes.Buffer
+	buf.Write(utilitySubPrefix)
+	l, _ := types.Encode(types.NewUCompactFromUInt(uint64(len(sorted))))
+	buf.Write(l)
+	for _, s := range sorted {
+		buf.Write(s)
+	}
+	t := make([]byte, 2)
+	binary.LittleEndian.PutUint16(t, threshold)
+	buf.Write(t)
+	h := blake2b.Sum256(buf.Bytes())
+	return h[:]
+}
+
+// derivativeAccountId derives the account used by Utility.as_derivative
+func derivativeAccountId(who []byte, index uint16) []byte {
+	var buf bytes.Buffer
+	buf.Write(utilitySubPrefix)
+	buf.Write(who)
+	t := make([]byte, 2)
+	binary.LittleEndian.PutUint16(t, index)
+	buf.Write(t)
+	h := blake2b.Sum256(buf.Bytes())
+	return h[:]
+}
+
+// matchTransfer finds the first unused transfer call in the extrinsic matching the Balances.Transfer event
+func matchTransfer(calls []*transferCall, tr *transferEvent) *transferCall {
+	for _, tc := range calls {
+		if tc.used {
+			continue
+		}
+		if tc.origin != nil && !bytes.Equal(tc.origin, tr.from) {
+			continue
+		}
+		if to := accountIdFromValue(tc.dest); to != nil && !bytes.Equal(to, tr.to) {
+			continue
+		}
+		if tc.amount != nil && tc.amount.Cmp(tr.amount) != 0 {
+			continue
+		}
+		tc.used = true
+		return tc
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package client
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/DataHighway-DHX/substrate-go/base"
 
@@ -22,6 +23,27 @@ type Client struct {
 	genesisHash    types.Hash
 	url            string
 	NetId          uint8
+	// lifetime in blocks of signed transactions, DefaultEraPeriod when zero
+	EraPeriod uint64
+	// sign immortal transactions, they can be replayed once the sender account is reaped
+	ImmortalEra bool
+	// native token decimals and symbol, from system_properties or the ss58 registry
+	Decimals int
+	Symbol   string
+	// optional, when set every parsed block is indexed for FindExtrinsic
+	TxIndex *TxIndex
+	// dry run transfers with system_dryRun and refuse the ones that would fail
+	DryRunTransfers bool
+	// check the sender balance and the existential deposit before submitting transfers
+	CheckBalances bool
+	// optional, receives the warnings of the balance checks, e.g. a transfer that reaps its sender
+	OnWarning func(error)
+	// optional, when set nonces are handed out locally instead of read from System.Account
+	Nonces *NonceManager
+	// metadata of older runtimes by spec version
+	metaBySpec map[types.U32]*types.Metadata
+	// guards Meta, RuntimeVersion, genesisHash and metaBySpec, which are refreshed while transactions are signed
+	mu sync.RWMutex
 }
 
 func New(url string, noPalletIndices bool) (*Client, error) {
@@ -44,11 +66,13 @@ func New(url string, noPalletIndices bool) (*Client, error) {
 		return nil, err
 	}
 
-	netId, err := c.BasicType.GetNetworkId(c.RuntimeVersion.SpecName)
+	_, rv := c.runtime()
+	netId, err := c.BasicType.GetNetworkId(rv.SpecName)
 	if err != nil {
 		return nil, err
 	}
 	c.NetId = netId
+	c.loadTokenInfo()
 	// expand.SetSerDeOptions(noPalletIndices)
 	return c, nil
 }
@@ -85,16 +109,61 @@ func (c *Client) checkRuntimeVersion() error {
 		}
 	}
 
-	if c.RuntimeVersion != v {
-		c.Meta, err = c.API.RPC.State.GetMetadataLatest()
-		if err != nil {
-			return fmt.Errorf("init metadata error: %v", err)
-		}
-		c.RuntimeVersion = v
+	meta, rv := c.runtime()
+	if meta != nil && rv != nil && rv.SpecVersion == v.SpecVersion && rv.TransactionVersion == v.TransactionVersion {
+		return nil
 	}
+	m, err := c.API.RPC.State.GetMetadataLatest()
+	if err != nil {
+		return fmt.Errorf("init metadata error: %v", err)
+	}
+	c.mu.Lock()
+	c.Meta, c.RuntimeVersion = m, v
+	c.mu.Unlock()
 	return nil
 }
 
+// runtime returns the latest metadata and runtime version, they are replaced together on a runtime upgrade
+func (c *Client) runtime() (*types.Metadata, *types.RuntimeVersion) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Meta, c.RuntimeVersion
+}
+
+// metadata returns the latest metadata
+func (c *Client) metadata() *types.Metadata {
+	m, _ := c.runtime()
+	return m
+}
+
+// metadataAt returns the metadata of the runtime a block was produced with
+func (c *Client) metadataAt(blockHash types.Hash) (*types.Metadata, error) {
+	v, err := c.API.RPC.State.GetRuntimeVersion(blockHash)
+	if err != nil {
+		return nil, fmt.Errorf("get runtime version error: %v", err)
+	}
+	c.mu.RLock()
+	m, ok := c.metaBySpec[v.SpecVersion]
+	if c.RuntimeVersion != nil && c.Meta != nil && v.SpecVersion == c.RuntimeVersion.SpecVersion {
+		m, ok = c.Meta, true
+	}
+	c.mu.RUnlock()
+	if ok {
+		return m, nil
+	}
+	m, err = c.API.RPC.State.GetMetadata(blockHash)
+	if err != nil {
+		return nil, fmt.Errorf("get metadata error: %v", err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.metaBySpec == nil {
+		c.metaBySpec = make(map[types.U32]*types.Metadata)
+	}
+	c.metaBySpec[v.SpecVersion] = m
+	return m, nil
+}
+
 type ChainInfo struct {
 	Chain       types.Text
 	NodeName    types.Text
@@ -119,16 +188,20 @@ func (c *Client) ChainInfo() (ci *ChainInfo, err error) {
 }
 
 func (c *Client) GetGenesisHash() (*types.Hash, error) {
-	emptyH := types.Hash{}
-	if c.genesisHash.Hex() != emptyH.Hex() {
-		return &c.genesisHash, nil
+	c.mu.RLock()
+	hash := c.genesisHash
+	c.mu.RUnlock()
+	if hash != (types.Hash{}) {
+		return &hash, nil
 	}
 	hash, err := c.API.RPC.Chain.GetBlockHash(0)
 	if err != nil {
 		return nil, fmt.Errorf("can't get genesis hash")
 	}
+	c.mu.Lock()
 	c.genesisHash = hash
-	return &c.genesisHash, nil
+	c.mu.Unlock()
+	return &hash, nil
 }
 
 // Customize the prefix. If the prefix loaded at startup is wrong, you need to configure the prefix manually
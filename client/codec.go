@@ -0,0 +1,469 @@
+package client
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/scale"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+/*
+Decode SCALE data against the metadata V14 type registry into generic Go values:
+bool, string, uint64, int64, *big.Int, HexBytes, []interface{},
+map[string]interface{}, *Variant and *DecodedCall
+*/
+
+// HexBytes is a byte slice that marshals to a 0x-prefixed hex string
+type HexBytes []byte
+
+func (h HexBytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal("0x" + hex.EncodeToString(h))
+}
+
+// Variant is a decoded enum value. Value is nil for variants without fields.
+type Variant struct {
+	Name  string      `json:"name"`
+	Index uint8       `json:"index"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+type DecodedArg struct {
+	Name     string      `json:"name"`
+	TypeName string      `json:"type_name"`
+	Value    interface{} `json:"value"`
+}
+
+// DecodedCall is a runtime call with its arguments decoded against the metadata
+type DecodedCall struct {
+	Pallet    string          `json:"pallet"`
+	Call      string          `json:"call"`
+	CallIndex types.CallIndex `json:"call_index"`
+	Args      []DecodedArg    `json:"args"`
+}
+
+func (dc *DecodedCall) Arg(name string) (interface{}, bool) {
+	for _, a := range dc.Args {
+		if a.Name == name {
+			return a.Value, true
+		}
+	}
+	return nil, false
+}
+
+type typeDecoder struct {
+	meta     *types.MetadataV14
+	callType int64
+	r        *bytes.Reader
+}
+
+func newTypeDecoder(m *types.Metadata, data []byte) (*typeDecoder, error) {
+	if m == nil || m.Version != 14 {
+		return nil, fmt.Errorf("metadata V14 is required")
+	}
+	callType, err := runtimeCallType(&m.AsMetadataV14)
+	if err != nil {
+		return nil, err
+	}
+	return &typeDecoder{
+		meta:     &m.AsMetadataV14,
+		callType: callType,
+		r:        bytes.NewReader(data),
+	}, nil
+}
+
+// runtimeCallType returns the lookup id of the outer runtime call enum
+func runtimeCallType(m *types.MetadataV14) (int64, error) {
+	if ext, ok := m.EfficientLookup[m.Extrinsic.Type.Int64()]; ok {
+		for _, p := range ext.Params {
+			if string(p.Name) == "Call" && p.HasType {
+				return p.Type.Int64(), nil
+			}
+		}
+	}
+	for _, t := range m.Lookup.Types {
+		path := t.Type.Path
+		if len(path) == 2 && (path[1] == "Call" || path[1] == "RuntimeCall") && t.Type.Def.IsVariant {
+			return t.ID.Int64(), nil
+		}
+	}
+	return 0, fmt.Errorf("can't find runtime call type in metadata")
+}
+
+// decodeCall decodes a call (call index followed by its arguments) from raw bytes
+func decodeCall(m *types.Metadata, data []byte) (*DecodedCall, error) {
+	d, err := newTypeDecoder(m, data)
+	if err != nil {
+		return nil, err
+	}
+	return d.decodeCall()
+}
+
+func (d *typeDecoder) remaining() int {
+	return d.r.Len()
+}
+
+func (d *typeDecoder) read(n int) ([]byte, error) {
+	if n < 0 || n > d.r.Len() {
+		return nil, fmt.Errorf("need %d bytes, only %d left", n, d.r.Len())
+	}
+	b := make([]byte, n)
+	_, err := io.ReadFull(d.r, b)
+	return b, err
+}
+
+func (d *typeDecoder) readByte() (byte, error) {
+	return d.r.ReadByte()
+}
+
+func (d *typeDecoder) readCompact() (*big.Int, error) {
+	if d.r.Len() == 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return scale.NewDecoder(d.r).DecodeUintCompact()
+}
+
+func (d *typeDecoder) lookup(id int64) (*types.Si1Type, error) {
+	t, ok := d.meta.EfficientLookup[id]
+	if !ok {
+		return nil, fmt.Errorf("type %d not found in metadata", id)
+	}
+	return t, nil
+}
+
+func (d *typeDecoder) decodeCall() (*DecodedCall, error) {
+	palletIndex, err := d.readByte()
+	if err != nil {
+		return nil, fmt.Errorf("read pallet index error: %v", err)
+	}
+	callIndex, err := d.readByte()
+	if err != nil {
+		return nil, fmt.Errorf("read call index error: %v", err)
+	}
+	for _, mod := range d.meta.Pallets {
+		if !mod.HasCalls || uint8(mod.Index) != palletIndex {
+			continue
+		}
+		ct, err := d.lookup(mod.Calls.Type.Int64())
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range ct.Def.Variant.Variants {
+			if uint8(v.Index) != callIndex {
+				continue
+			}
+			dc := &DecodedCall{
+				Pallet:    string(mod.Name),
+				Call:      string(v.Name),
+				CallIndex: types.CallIndex{SectionIndex: palletIndex, MethodIndex: callIndex},
+			}
+			for _, f := range v.Fields {
+				val, err := d.decode(f.Type.Int64())
+				if err != nil {
+					return nil, fmt.Errorf("decode %s.%s arg %s error: %v", dc.Pallet, dc.Call, f.Name, err)
+				}
+				dc.Args = append(dc.Args, DecodedArg{Name: string(f.Name), TypeName: string(f.TypeName), Value: val})
+			}
+			return dc, nil
+		}
+		return nil, fmt.Errorf("call %d not found in pallet %s", callIndex, mod.Name)
+	}
+	return nil, fmt.Errorf("pallet %d not found in metadata", palletIndex)
+}
+
+func (d *typeDecoder) decode(id int64) (interface{}, error) {
+	if id == d.callType {
+		return d.decodeCall()
+	}
+	t, err := d.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	def := t.Def
+	switch {
+	case def.IsComposite:
+		fields := def.Composite.Fields
+		if len(t.Path) > 0 && t.Path[len(t.Path)-1] == "WrapperKeepOpaque" && len(fields) == 2 {
+			// length prefixed call, only the call itself is of interest
+			if _, err := d.readCompact(); err != nil {
+				return nil, err
+			}
+			return d.decode(fields[1].Type.Int64())
+		}
+		return d.decodeFields(fields)
+	case def.IsVariant:
+		b, err := d.readByte()
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range def.Variant.Variants {
+			if uint8(v.Index) != b {
+				continue
+			}
+			val, err := d.decodeFields(v.Fields)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", v.Name, err)
+			}
+			if len(t.Path) == 1 && t.Path[0] == "Option" {
+				return val, nil
+			}
+			return &Variant{Name: string(v.Name), Index: b, Value: val}, nil
+		}
+		return nil, fmt.Errorf("variant index %d not found in type %d", b, id)
+	case def.IsSequence:
+		n, err := d.readCompact()
+		if err != nil {
+			return nil, err
+		}
+		if !n.IsInt64() || n.Int64() > int64(d.remaining()) {
+			return nil, fmt.Errorf("sequence length %s is out of range", n.String())
+		}
+		return d.decodeList(def.Sequence.Type.Int64(), int(n.Int64()))
+	case def.IsArray:
+		return d.decodeList(def.Array.Type.Int64(), int(def.Array.Len))
+	case def.IsTuple:
+		if len(def.Tuple) == 0 {
+			return nil, nil
+		}
+		vals := make([]interface{}, 0, len(def.Tuple))
+		for _, tid := range def.Tuple {
+			v, err := d.decode(tid.Int64())
+			if err != nil {
+				return nil, err
+			}
+			vals = append(vals, v)
+		}
+		return vals, nil
+	case def.IsPrimitive:
+		return d.decodePrimitive(def.Primitive.Si0TypeDefPrimitive)
+	case def.IsCompact:
+		return d.readCompact()
+	case def.IsBitSequence:
+		n, err := d.readCompact()
+		if err != nil {
+			return nil, err
+		}
+		storeSize := 1
+		if st, err := d.lookup(def.BitSequence.BitStoreType.Int64()); err == nil && st.Def.IsPrimitive {
+			storeSize = primitiveSize(st.Def.Primitive.Si0TypeDefPrimitive)
+		}
+		bits := int(n.Int64())
+		words := (bits + storeSize*8 - 1) / (storeSize * 8)
+		b, err := d.read(words * storeSize)
+		if err != nil {
+			return nil, err
+		}
+		return HexBytes(b), nil
+	}
+	return nil, fmt.Errorf("unsupported type definition for type %d", id)
+}
+
+func (d *typeDecoder) decodeFields(fields []types.Si1Field) (interface{}, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	if len(fields) == 1 && !fields[0].HasName {
+		return d.decode(fields[0].Type.Int64())
+	}
+	if !fields[0].HasName {
+		vals := make([]interface{}, 0, len(fields))
+		for _, f := range fields {
+			v, err := d.decode(f.Type.Int64())
+			if err != nil {
+				return nil, err
+			}
+			vals = append(vals, v)
+		}
+		return vals, nil
+	}
+	vals := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		v, err := d.decode(f.Type.Int64())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", f.Name, err)
+		}
+		vals[string(f.Name)] = v
+	}
+	return vals, nil
+}
+
+func (d *typeDecoder) decodeList(elem int64, n int) (interface{}, error) {
+	if et, err := d.lookup(elem); err == nil && et.Def.IsPrimitive && et.Def.Primitive.Si0TypeDefPrimitive == types.IsU8 {
+		b, err := d.read(n)
+		if err != nil {
+			return nil, err
+		}
+		return HexBytes(b), nil
+	}
+	vals := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		v, err := d.decode(elem)
+		if err != nil {
+			return nil, fmt.Errorf("[%d]: %v", i, err)
+		}
+		vals = append(vals, v)
+	}
+	return vals, nil
+}
+
+func primitiveSize(p types.Si0TypeDefPrimitive) int {
+	switch p {
+	case types.IsBool, types.IsU8, types.IsI8:
+		return 1
+	case types.IsU16, types.IsI16:
+		return 2
+	case types.IsU32, types.IsI32, types.IsChar:
+		return 4
+	case types.IsU64, types.IsI64:
+		return 8
+	case types.IsU128, types.IsI128:
+		return 16
+	case types.IsU256, types.IsI256:
+		return 32
+	}
+	return 0
+}
+
+func (d *typeDecoder) decodePrimitive(p types.Si0TypeDefPrimitive) (interface{}, error) {
+	if p == types.IsStr {
+		n, err := d.readCompact()
+		if err != nil {
+			return nil, err
+		}
+		if !n.IsInt64() || n.Int64() > int64(d.remaining()) {
+			return nil, fmt.Errorf("string length %s is out of range", n.String())
+		}
+		b, err := d.read(int(n.Int64()))
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	}
+	size := primitiveSize(p)
+	if size == 0 {
+		return nil, fmt.Errorf("unsupported primitive %d", p)
+	}
+	b, err := d.read(size)
+	if err != nil {
+		return nil, err
+	}
+	switch p {
+	case types.IsBool:
+		return b[0] == 1, nil
+	case types.IsChar:
+		return string(rune(binary.LittleEndian.Uint32(b))), nil
+	case types.IsU8:
+		return uint64(b[0]), nil
+	case types.IsU16:
+		return uint64(binary.LittleEndian.Uint16(b)), nil
+	case types.IsU32:
+		return uint64(binary.LittleEndian.Uint32(b)), nil
+	case types.IsU64:
+		return binary.LittleEndian.Uint64(b), nil
+	case types.IsI8:
+		return int64(int8(b[0])), nil
+	case types.IsI16:
+		return int64(int16(binary.LittleEndian.Uint16(b))), nil
+	case types.IsI32:
+		return int64(int32(binary.LittleEndian.Uint32(b))), nil
+	case types.IsI64:
+		return int64(binary.LittleEndian.Uint64(b)), nil
+	case types.IsU128, types.IsU256:
+		scale.Reverse(b)
+		return new(big.Int).SetBytes(b), nil
+	default:
+		// i128 / i256, two's complement
+		scale.Reverse(b)
+		v := new(big.Int).SetBytes(b)
+		if b[0]&0x80 != 0 {
+			v.Sub(v, new(big.Int).Lsh(big.NewInt(1), uint(size*8)))
+		}
+		return v, nil
+	}
+}
+
+// storageValueType returns the lookup id of the value type of a storage entry
+func storageValueType(m *types.MetadataV14, pallet, item string) (int64, error) {
+	for _, mod := range m.Pallets {
+		if !mod.HasStorage || string(mod.Name) != pallet {
+			continue
+		}
+		for _, s := range mod.Storage.Items {
+			if string(s.Name) != item {
+				continue
+			}
+			if s.Type.IsMap {
+				return s.Type.AsMap.Value.Int64(), nil
+			}
+			return s.Type.AsPlainType.Int64(), nil
+		}
+	}
+	return 0, fmt.Errorf("storage %s.%s not found in metadata", pallet, item)
+}
+
+// decodeStorageValue decodes a raw storage value of pallet.item into generic values
+func decodeStorageValue(m *types.Metadata, pallet, item string, raw []byte) (interface{}, error) {
+	d, err := newTypeDecoder(m, raw)
+	if err != nil {
+		return nil, err
+	}
+	id, err := storageValueType(d.meta, pallet, item)
+	if err != nil {
+		return nil, err
+	}
+	v, err := d.decode(id)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s.%s error: %v", pallet, item, err)
+	}
+	return v, nil
+}
+
+// typeByPath returns the lookup id of the type with the given path, e.g. sp_runtime DispatchError
+func typeByPath(m *types.MetadataV14, path ...string) (int64, bool) {
+	for _, t := range m.Lookup.Types {
+		if len(t.Type.Path) != len(path) {
+			continue
+		}
+		match := true
+		for i, p := range path {
+			if string(t.Type.Path[i]) != p {
+				match = false
+				break
+			}
+		}
+		if match {
+			return t.ID.Int64(), true
+		}
+	}
+	return 0, false
+}
+
+// decodeConstant decodes the value of pallet constant name into generic values
+func decodeConstant(m *types.Metadata, pallet, name string) (interface{}, error) {
+	d, err := newTypeDecoder(m, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, mod := range d.meta.Pallets {
+		if string(mod.Name) != pallet {
+			continue
+		}
+		for _, cm := range mod.Constants {
+			if string(cm.Name) != name {
+				continue
+			}
+			d.r = bytes.NewReader(cm.Value)
+			v, err := d.decode(cm.Type.Int64())
+			if err != nil {
+				return nil, fmt.Errorf("decode %s.%s error: %v", pallet, name, err)
+			}
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("constant %s.%s not found in metadata", pallet, name)
+}
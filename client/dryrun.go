@@ -0,0 +1,134 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+// DryRunResult is the decoded ApplyExtrinsicResult of system_dryRun
+type DryRunResult struct {
+	Success bool `json:"success"`
+	// TransactionValidityError, e.g. Invalid.Payment or Invalid.Stale, the pool would reject the transaction
+	Validity string `json:"validity,omitempty"`
+	// DispatchError, e.g. Balances.InsufficientBalance, the transaction would be included and fail
+	DispatchError string `json:"dispatch_error,omitempty"`
+}
+
+// DryRunError reports a transaction that would be rejected or fail
+type DryRunError struct {
+	Validity      string
+	DispatchError string
+}
+
+func (e *DryRunError) Error() string {
+	if e.Validity != "" {
+		return "transaction would be rejected: " + e.Validity
+	}
+	return "transaction would fail: " + e.DispatchError
+}
+
+// Err returns a *DryRunError unless the dry run succeeded
+func (r *DryRunResult) Err() error {
+	if r.Success {
+		return nil
+	}
+	return &DryRunError{Validity: r.Validity, DispatchError: r.DispatchError}
+}
+
+var (
+	invalidTransaction = []string{"Call", "Payment", "Future", "Stale", "BadProof", "AncientBirthBlock",
+		"ExhaustsResources", "Custom", "BadMandatory", "MandatoryValidation", "BadSigner"}
+	unknownTransaction = []string{"CannotLookup", "NoUnsignedValidator", "Custom"}
+)
+
+/*
+Apply a signed extrinsic with system_dryRun on top of atBlock, or the best block when atBlock
+is nil, without broadcasting it. system_dryRun is an unsafe RPC, the node must allow it.
+*/
+func (c *Client) DryRun(ext types.Extrinsic, atBlock *types.Hash) (*DryRunResult, error) {
+	meta := c.metadata()
+	params := []interface{}{ext}
+	if atBlock != nil {
+		var err error
+		meta, err = c.metadataAt(*atBlock)
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, atBlock.Hex())
+	}
+	var res string
+	err := c.API.Client.Call(&res, "system_dryRun", params...)
+	if err != nil {
+		return nil, fmt.Errorf("system_dryRun error: %v", err)
+	}
+	b, err := types.HexDecodeString(res)
+	if err != nil {
+		return nil, fmt.Errorf("invalid system_dryRun result %s: %v", res, err)
+	}
+	return DecodeApplyExtrinsicResult(meta, b)
+}
+
+// DecodeApplyExtrinsicResult decodes Result<Result<(), DispatchError>, TransactionValidityError>
+func DecodeApplyExtrinsicResult(m *types.Metadata, b []byte) (*DryRunResult, error) {
+	if len(b) < 2 {
+		return nil, fmt.Errorf("apply extrinsic result is too short: %#x", b)
+	}
+	switch b[0] {
+	case 0:
+		if b[1] == 0 {
+			return &DryRunResult{Success: true}, nil
+		}
+		if b[1] != 1 {
+			return nil, fmt.Errorf("invalid dispatch outcome %d", b[1])
+		}
+		d, err := newTypeDecoder(m, b[2:])
+		if err != nil {
+			return nil, err
+		}
+		id, ok := typeByPath(d.meta, "sp_runtime", "DispatchError")
+		if !ok {
+			return nil, fmt.Errorf("DispatchError not found in metadata")
+		}
+		de, err := d.decode(id)
+		if err != nil {
+			return nil, fmt.Errorf("decode DispatchError error: %v", err)
+		}
+		return &DryRunResult{DispatchError: dispatchErrorString(m, de)}, nil
+	case 1:
+		if len(b) < 3 {
+			return nil, fmt.Errorf("transaction validity error is too short: %#x", b)
+		}
+		kind, names := "Invalid", invalidTransaction
+		if b[1] == 1 {
+			kind, names = "Unknown", unknownTransaction
+		} else if b[1] != 0 {
+			return nil, fmt.Errorf("invalid transaction validity error %d", b[1])
+		}
+		if int(b[2]) >= len(names) {
+			return &DryRunResult{Validity: fmt.Sprintf("%s(%d)", kind, b[2])}, nil
+		}
+		v := kind + "." + names[b[2]]
+		if names[b[2]] == "Custom" && len(b) > 3 {
+			v = fmt.Sprintf("%s(%d)", v, b[3])
+		}
+		return &DryRunResult{Validity: v}, nil
+	}
+	return nil, fmt.Errorf("invalid apply extrinsic result %d", b[0])
+}
+
+// preflight dry runs ext when DryRunTransfers is set, a failing ext hands its nonce back
+func (c *Client) preflight(ext types.Extrinsic) error {
+	if !c.DryRunTransfers {
+		return nil
+	}
+	res, err := c.DryRun(ext, nil)
+	if err == nil {
+		err = res.Err()
+	}
+	if err != nil {
+		c.submitFailed(ext, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,660 @@
+package client
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/scale"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+/*
+Encode Go values against the metadata V14 type registry, the counterpart of the decoder in codec.go.
+Accepted values are the ones the decoder produces plus their usual Go and JSON forms:
+  - integers of any Go type, *big.Int, json.Number, integral float64 and decimal or 0x strings
+  - []byte, HexBytes or 0x hex strings for byte sequences and arrays, plain strings for Vec<u8>
+  - map[string]interface{} for structs with named fields, []interface{} for tuples and sequences
+  - *Variant, {"Name": value} or "Name" for enums, nil or the value itself for Option
+  - an account id for MultiAddress, types.Call or {"call": "Pallet.call", "args": {...}} for calls
+Any other value (e.g. types.MultiAddress) is SCALE encoded as is and checked to decode as the declared type.
+*/
+
+type typeEncoder struct {
+	meta     *types.MetadataV14
+	callType int64
+	buf      *bytes.Buffer
+}
+
+func newTypeEncoder(m *types.Metadata) (*typeEncoder, error) {
+	if m == nil || m.Version != 14 {
+		return nil, fmt.Errorf("metadata V14 is required")
+	}
+	callType, err := runtimeCallType(&m.AsMetadataV14)
+	if err != nil {
+		return nil, err
+	}
+	return &typeEncoder{
+		meta:     &m.AsMetadataV14,
+		callType: callType,
+		buf:      new(bytes.Buffer),
+	}, nil
+}
+
+/*
+NewCall builds the call named "Pallet.call" from positional args. The number of args and
+their types are checked against the call fields in the metadata.
+*/
+func NewCall(m *types.Metadata, call string, args ...interface{}) (types.Call, error) {
+	if m != nil && m.Version != 14 {
+		return newCallUnchecked(m, call, args...)
+	}
+	e, err := newTypeEncoder(m)
+	if err != nil {
+		return types.Call{}, err
+	}
+	ci, fields, err := e.findCall(call)
+	if err != nil {
+		return types.Call{}, err
+	}
+	if len(args) != len(fields) {
+		return types.Call{}, fmt.Errorf("%s takes %d args, got %d", call, len(fields), len(args))
+	}
+	for i, f := range fields {
+		err = e.encode(f.Type.Int64(), args[i])
+		if err != nil {
+			return types.Call{}, fmt.Errorf("%s arg %s: %v", call, f.Name, err)
+		}
+	}
+	return types.Call{CallIndex: ci, Args: e.buf.Bytes()}, nil
+}
+
+// NewCallWithArgs builds the call named "Pallet.call" from args keyed by field name
+func NewCallWithArgs(m *types.Metadata, call string, args map[string]interface{}) (types.Call, error) {
+	e, err := newTypeEncoder(m)
+	if err != nil {
+		return types.Call{}, err
+	}
+	ci, fields, err := e.findCall(call)
+	if err != nil {
+		return types.Call{}, err
+	}
+	err = e.encodeNamed(fields, args)
+	if err != nil {
+		return types.Call{}, fmt.Errorf("%s arg %v", call, err)
+	}
+	return types.Call{CallIndex: ci, Args: e.buf.Bytes()}, nil
+}
+
+// NewCallFromJSON builds the call named "Pallet.call" from a JSON object of named args or a JSON array of positional args
+func NewCallFromJSON(m *types.Metadata, call string, data []byte) (types.Call, error) {
+	args, err := decodeJSON(data)
+	if err != nil {
+		return types.Call{}, fmt.Errorf("invalid %s args: %v", call, err)
+	}
+	switch a := args.(type) {
+	case map[string]interface{}:
+		return NewCallWithArgs(m, call, a)
+	case []interface{}:
+		return NewCall(m, call, a...)
+	case nil:
+		return NewCallWithArgs(m, call, nil)
+	}
+	return types.Call{}, fmt.Errorf("%s args must be a JSON object or array", call)
+}
+
+func decodeJSON(data []byte) (interface{}, error) {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	err := dec.Decode(&v)
+	return v, err
+}
+
+// newCallUnchecked encodes args as is, for runtimes without a metadata V14 type registry
+func newCallUnchecked(m *types.Metadata, call string, args ...interface{}) (types.Call, error) {
+	c, err := m.FindCallIndex(call)
+	if err != nil {
+		return types.Call{}, err
+	}
+	var a []byte
+	for _, arg := range args {
+		e, err := types.Encode(arg)
+		if err != nil {
+			return types.Call{}, err
+		}
+		a = append(a, e...)
+	}
+	return types.Call{CallIndex: c, Args: a}, nil
+}
+
+func (e *typeEncoder) findCall(call string) (types.CallIndex, []types.Si1Field, error) {
+	s := strings.Split(call, ".")
+	if len(s) != 2 {
+		return types.CallIndex{}, nil, fmt.Errorf("invalid call name %q, want Pallet.call", call)
+	}
+	for _, mod := range e.meta.Pallets {
+		if !mod.HasCalls || string(mod.Name) != s[0] {
+			continue
+		}
+		ct, ok := e.meta.EfficientLookup[mod.Calls.Type.Int64()]
+		if !ok {
+			break
+		}
+		for _, v := range ct.Def.Variant.Variants {
+			if string(v.Name) == s[1] {
+				return types.CallIndex{SectionIndex: uint8(mod.Index), MethodIndex: uint8(v.Index)}, v.Fields, nil
+			}
+		}
+	}
+	return types.CallIndex{}, nil, fmt.Errorf("%w: %s", ErrCallNotFound, call)
+}
+
+func (e *typeEncoder) lookup(id int64) (*types.Si1Type, error) {
+	t, ok := e.meta.EfficientLookup[id]
+	if !ok {
+		return nil, fmt.Errorf("type %d not found in metadata", id)
+	}
+	return t, nil
+}
+
+func (e *typeEncoder) typeName(id int64) string {
+	t, err := e.lookup(id)
+	if err != nil {
+		return fmt.Sprintf("type %d", id)
+	}
+	if len(t.Path) > 0 {
+		path := make([]string, len(t.Path))
+		for i, p := range t.Path {
+			path[i] = string(p)
+		}
+		return strings.Join(path, "::")
+	}
+	switch {
+	case t.Def.IsPrimitive:
+		return primitiveName(t.Def.Primitive.Si0TypeDefPrimitive)
+	case t.Def.IsCompact:
+		return "Compact<" + e.typeName(t.Def.Compact.Type.Int64()) + ">"
+	case t.Def.IsSequence:
+		return "Vec<" + e.typeName(t.Def.Sequence.Type.Int64()) + ">"
+	case t.Def.IsArray:
+		return fmt.Sprintf("[%s; %d]", e.typeName(t.Def.Array.Type.Int64()), t.Def.Array.Len)
+	case t.Def.IsTuple:
+		names := make([]string, len(t.Def.Tuple))
+		for i, tid := range t.Def.Tuple {
+			names[i] = e.typeName(tid.Int64())
+		}
+		return "(" + strings.Join(names, ", ") + ")"
+	}
+	return fmt.Sprintf("type %d", id)
+}
+
+func primitiveName(p types.Si0TypeDefPrimitive) string {
+	names := map[types.Si0TypeDefPrimitive]string{
+		types.IsBool: "bool", types.IsChar: "char", types.IsStr: "str",
+		types.IsU8: "u8", types.IsU16: "u16", types.IsU32: "u32", types.IsU64: "u64", types.IsU128: "u128", types.IsU256: "u256",
+		types.IsI8: "i8", types.IsI16: "i16", types.IsI32: "i32", types.IsI64: "i64", types.IsI128: "i128", types.IsI256: "i256",
+	}
+	if n, ok := names[p]; ok {
+		return n
+	}
+	return fmt.Sprintf("primitive %d", p)
+}
+
+// generic converts v to one of the generic value forms, ok is false for values to be encoded as is
+func generic(v interface{}) (interface{}, bool) {
+	switch x := v.(type) {
+	case nil, bool, string, *big.Int, HexBytes, []interface{}, map[string]interface{}, *Variant, *DecodedCall, json.Number, float64:
+		return v, true
+	case []byte:
+		return HexBytes(x), true
+	case big.Int:
+		return &x, true
+	case types.UCompact:
+		b := big.Int(x)
+		return &b, true
+	case types.U128:
+		return x.Int, true
+	case types.U256:
+		return x.Int, true
+	case Variant:
+		return &x, true
+	case DecodedCall:
+		return &x, true
+	case types.Call:
+		b, err := types.Encode(x)
+		if err != nil {
+			return v, false
+		}
+		return HexBytes(b), true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return rv.Bool(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Int).SetUint64(rv.Uint()), true
+	case reflect.String:
+		return rv.String(), true
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return HexBytes(b), true
+		}
+	}
+	return v, false
+}
+
+// encodeTyped SCALE encodes a typed value and checks that it decodes as type id
+func (e *typeEncoder) encodeTyped(id int64, v interface{}) error {
+	b, err := types.Encode(v)
+	if err != nil {
+		return err
+	}
+	d := &typeDecoder{meta: e.meta, callType: e.callType, r: bytes.NewReader(b)}
+	if _, err := d.decode(id); err != nil || d.remaining() != 0 {
+		return fmt.Errorf("%T does not match %s", v, e.typeName(id))
+	}
+	e.buf.Write(b)
+	return nil
+}
+
+func (e *typeEncoder) encode(id int64, v interface{}) error {
+	v, ok := generic(v)
+	if !ok {
+		return e.encodeTyped(id, v)
+	}
+	if id == e.callType {
+		return e.encodeCall(v)
+	}
+	t, err := e.lookup(id)
+	if err != nil {
+		return err
+	}
+	def := t.Def
+	switch {
+	case def.IsComposite:
+		fields := def.Composite.Fields
+		if len(t.Path) > 0 && t.Path[len(t.Path)-1] == "WrapperKeepOpaque" && len(fields) == 2 {
+			inner := &typeEncoder{meta: e.meta, callType: e.callType, buf: new(bytes.Buffer)}
+			if err := inner.encode(fields[1].Type.Int64(), v); err != nil {
+				return err
+			}
+			if err := e.writeCompact(big.NewInt(int64(inner.buf.Len()))); err != nil {
+				return err
+			}
+			e.buf.Write(inner.buf.Bytes())
+			return nil
+		}
+		return e.encodeFields(id, fields, v)
+	case def.IsVariant:
+		return e.encodeVariant(id, t, v)
+	case def.IsSequence:
+		elem := def.Sequence.Type.Int64()
+		if e.isU8(elem) {
+			b, err := toBytes(v, true)
+			if err != nil {
+				return err
+			}
+			if err := e.writeCompact(big.NewInt(int64(len(b)))); err != nil {
+				return err
+			}
+			e.buf.Write(b)
+			return nil
+		}
+		list, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("expected a list for %s, got %T", e.typeName(id), v)
+		}
+		if err := e.writeCompact(big.NewInt(int64(len(list)))); err != nil {
+			return err
+		}
+		return e.encodeList(elem, list)
+	case def.IsArray:
+		elem, n := def.Array.Type.Int64(), int(def.Array.Len)
+		if e.isU8(elem) {
+			b, err := toBytes(v, false)
+			if err != nil {
+				return err
+			}
+			if len(b) != n {
+				return fmt.Errorf("expected %d bytes for %s, got %d", n, e.typeName(id), len(b))
+			}
+			e.buf.Write(b)
+			return nil
+		}
+		list, ok := v.([]interface{})
+		if !ok || len(list) != n {
+			return fmt.Errorf("expected a list of %d items for %s", n, e.typeName(id))
+		}
+		return e.encodeList(elem, list)
+	case def.IsTuple:
+		if len(def.Tuple) == 0 {
+			return nil
+		}
+		list, ok := v.([]interface{})
+		if !ok || len(list) != len(def.Tuple) {
+			return fmt.Errorf("expected a list of %d items for %s", len(def.Tuple), e.typeName(id))
+		}
+		for i, tid := range def.Tuple {
+			if err := e.encode(tid.Int64(), list[i]); err != nil {
+				return fmt.Errorf("[%d]: %v", i, err)
+			}
+		}
+		return nil
+	case def.IsPrimitive:
+		return e.encodePrimitive(def.Primitive.Si0TypeDefPrimitive, v)
+	case def.IsCompact:
+		n, err := toInteger(v)
+		if err != nil {
+			return err
+		}
+		if n.Sign() < 0 {
+			return fmt.Errorf("compact value %s is negative", n)
+		}
+		return e.writeCompact(n)
+	case def.IsBitSequence:
+		b, err := toBytes(v, false)
+		if err != nil {
+			return err
+		}
+		if err := e.writeCompact(big.NewInt(int64(len(b) * 8))); err != nil {
+			return err
+		}
+		e.buf.Write(b)
+		return nil
+	}
+	return fmt.Errorf("unsupported type definition for type %d", id)
+}
+
+func (e *typeEncoder) encodeCall(v interface{}) error {
+	switch c := v.(type) {
+	case HexBytes:
+		d := &typeDecoder{meta: e.meta, callType: e.callType, r: bytes.NewReader(c)}
+		if _, err := d.decodeCall(); err != nil || d.remaining() != 0 {
+			return fmt.Errorf("invalid encoded call %#x", []byte(c))
+		}
+		e.buf.Write(c)
+		return nil
+	case *DecodedCall:
+		args := make(map[string]interface{}, len(c.Args))
+		for _, a := range c.Args {
+			args[a.Name] = a.Value
+		}
+		return e.encodeNamedCall(c.Pallet+"."+c.Call, args)
+	case map[string]interface{}:
+		name, _ := c["call"].(string)
+		if name == "" {
+			return fmt.Errorf("call must name a Pallet.call in \"call\"")
+		}
+		switch args := c["args"].(type) {
+		case map[string]interface{}:
+			return e.encodeNamedCall(name, args)
+		case nil:
+			return e.encodeNamedCall(name, nil)
+		default:
+			return fmt.Errorf("%s args must be an object", name)
+		}
+	}
+	return fmt.Errorf("expected a call, got %T", v)
+}
+
+func (e *typeEncoder) encodeNamedCall(name string, args map[string]interface{}) error {
+	ci, fields, err := e.findCall(name)
+	if err != nil {
+		return err
+	}
+	e.buf.WriteByte(ci.SectionIndex)
+	e.buf.WriteByte(ci.MethodIndex)
+	if err := e.encodeNamed(fields, args); err != nil {
+		return fmt.Errorf("%s arg %v", name, err)
+	}
+	return nil
+}
+
+func (e *typeEncoder) encodeVariant(id int64, t *types.Si1Type, v interface{}) error {
+	variants := t.Def.Variant.Variants
+	byName := func(name string) *types.Si1Variant {
+		for i := range variants {
+			if string(variants[i].Name) == name {
+				return &variants[i]
+			}
+		}
+		return nil
+	}
+	write := func(sv *types.Si1Variant, val interface{}) error {
+		e.buf.WriteByte(byte(sv.Index))
+		if err := e.encodeFields(id, sv.Fields, val); err != nil {
+			return fmt.Errorf("%s: %v", sv.Name, err)
+		}
+		return nil
+	}
+
+	if len(t.Path) == 1 && t.Path[0] == "Option" {
+		if _, isVariant := v.(*Variant); !isVariant {
+			if v == nil {
+				e.buf.WriteByte(0)
+				return nil
+			}
+			if some := byName("Some"); some != nil {
+				return write(some, v)
+			}
+		}
+	}
+
+	switch x := v.(type) {
+	case *Variant:
+		if sv := byName(x.Name); sv != nil {
+			return write(sv, x.Value)
+		}
+		return fmt.Errorf("variant %s not found in %s", x.Name, e.typeName(id))
+	case string:
+		if sv := byName(x); sv != nil {
+			return write(sv, nil)
+		}
+	case map[string]interface{}:
+		if len(x) == 1 {
+			for name, val := range x {
+				if sv := byName(name); sv != nil {
+					return write(sv, val)
+				}
+			}
+		}
+	}
+	// an account id is accepted for a MultiAddress
+	if len(t.Path) > 0 && t.Path[len(t.Path)-1] == "MultiAddress" {
+		if sv := byName("Id"); sv != nil {
+			return write(sv, v)
+		}
+	}
+	return fmt.Errorf("expected a variant of %s, got %v", e.typeName(id), v)
+}
+
+func (e *typeEncoder) encodeFields(id int64, fields []types.Si1Field, v interface{}) error {
+	if len(fields) == 0 {
+		if v != nil {
+			return fmt.Errorf("%s has no fields, got %v", e.typeName(id), v)
+		}
+		return nil
+	}
+	if len(fields) == 1 && !fields[0].HasName {
+		return e.encode(fields[0].Type.Int64(), v)
+	}
+	if !fields[0].HasName {
+		list, ok := v.([]interface{})
+		if !ok || len(list) != len(fields) {
+			return fmt.Errorf("expected a list of %d items for %s", len(fields), e.typeName(id))
+		}
+		for i, f := range fields {
+			if err := e.encode(f.Type.Int64(), list[i]); err != nil {
+				return fmt.Errorf("[%d]: %v", i, err)
+			}
+		}
+		return nil
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected an object for %s, got %T", e.typeName(id), v)
+	}
+	return e.encodeNamed(fields, m)
+}
+
+func (e *typeEncoder) encodeNamed(fields []types.Si1Field, args map[string]interface{}) error {
+	known := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		known[string(f.Name)] = true
+	}
+	for name := range args {
+		if !known[name] {
+			return fmt.Errorf("%s: unknown field", name)
+		}
+	}
+	for _, f := range fields {
+		v, ok := args[string(f.Name)]
+		if !ok {
+			return fmt.Errorf("%s: missing", f.Name)
+		}
+		if err := e.encode(f.Type.Int64(), v); err != nil {
+			return fmt.Errorf("%s: %v", f.Name, err)
+		}
+	}
+	return nil
+}
+
+func (e *typeEncoder) encodeList(elem int64, list []interface{}) error {
+	for i, item := range list {
+		if err := e.encode(elem, item); err != nil {
+			return fmt.Errorf("[%d]: %v", i, err)
+		}
+	}
+	return nil
+}
+
+func (e *typeEncoder) isU8(id int64) bool {
+	t, err := e.lookup(id)
+	return err == nil && t.Def.IsPrimitive && t.Def.Primitive.Si0TypeDefPrimitive == types.IsU8
+}
+
+func (e *typeEncoder) writeCompact(n *big.Int) error {
+	return scale.NewEncoder(e.buf).EncodeUintCompact(*n)
+}
+
+func (e *typeEncoder) encodePrimitive(p types.Si0TypeDefPrimitive, v interface{}) error {
+	switch p {
+	case types.IsBool:
+		b, ok := v.(bool)
+		if !ok {
+			return fmt.Errorf("expected bool, got %T", v)
+		}
+		if b {
+			e.buf.WriteByte(1)
+		} else {
+			e.buf.WriteByte(0)
+		}
+		return nil
+	case types.IsStr:
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("expected string, got %T", v)
+		}
+		if err := e.writeCompact(big.NewInt(int64(len(s)))); err != nil {
+			return err
+		}
+		e.buf.WriteString(s)
+		return nil
+	case types.IsChar:
+		s, ok := v.(string)
+		if !ok || len([]rune(s)) != 1 {
+			return fmt.Errorf("expected a single character, got %v", v)
+		}
+		b := make([]byte, 4)
+		binary.LittleEndian.PutUint32(b, uint32([]rune(s)[0]))
+		e.buf.Write(b)
+		return nil
+	}
+
+	size := primitiveSize(p)
+	if size == 0 {
+		return fmt.Errorf("unsupported primitive %d", p)
+	}
+	n, err := toInteger(v)
+	if err != nil {
+		return err
+	}
+	signed := p == types.IsI8 || p == types.IsI16 || p == types.IsI32 || p == types.IsI64 || p == types.IsI128 || p == types.IsI256
+	bits := uint(size * 8)
+	min, max := new(big.Int), new(big.Int).Lsh(big.NewInt(1), bits)
+	if signed {
+		max.Rsh(max, 1)
+		min.Neg(max)
+	}
+	if n.Cmp(min) < 0 || n.Cmp(max) >= 0 {
+		return fmt.Errorf("%s is out of range for %s", n, primitiveName(p))
+	}
+	if n.Sign() < 0 {
+		// two's complement
+		n = new(big.Int).Add(n, new(big.Int).Lsh(big.NewInt(1), bits))
+	}
+	b := make([]byte, size)
+	n.FillBytes(b)
+	scale.Reverse(b)
+	e.buf.Write(b)
+	return nil
+}
+
+// toInteger converts a generic number form to an integer
+func toInteger(v interface{}) (*big.Int, error) {
+	switch x := v.(type) {
+	case *big.Int:
+		if x == nil {
+			return nil, fmt.Errorf("expected an integer, got nil")
+		}
+		return x, nil
+	case json.Number:
+		return parseInteger(string(x))
+	case string:
+		return parseInteger(x)
+	case float64:
+		if x != math.Trunc(x) || math.IsInf(x, 0) || math.Abs(x) > 1<<53 {
+			return nil, fmt.Errorf("%v is not an exact integer", x)
+		}
+		return big.NewInt(int64(x)), nil
+	}
+	return nil, fmt.Errorf("expected an integer, got %T", v)
+}
+
+func parseInteger(s string) (*big.Int, error) {
+	n, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer %q", s)
+	}
+	return n, nil
+}
+
+// toBytes converts a generic byte form, plain strings are taken as text when text is true
+func toBytes(v interface{}, text bool) ([]byte, error) {
+	switch x := v.(type) {
+	case HexBytes:
+		return x, nil
+	case string:
+		if strings.HasPrefix(x, "0x") {
+			b, err := types.HexDecodeString(x)
+			if err != nil {
+				return nil, fmt.Errorf("invalid hex %q: %v", x, err)
+			}
+			return b, nil
+		}
+		if text {
+			return []byte(x), nil
+		}
+	}
+	return nil, fmt.Errorf("expected bytes, got %T", v)
+}
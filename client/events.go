@@ -0,0 +1,173 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+// DecodedEvent is a runtime event with its fields decoded against the metadata
+type DecodedEvent struct {
+	Pallet         string      `json:"pallet"`
+	Name           string      `json:"name"`
+	Phase          string      `json:"phase"`           // ApplyExtrinsic, Finalization or Initialization
+	ExtrinsicIndex int         `json:"extrinsic_index"` // -1 unless Phase is ApplyExtrinsic
+	Fields         interface{} `json:"fields,omitempty"`
+}
+
+func (e *DecodedEvent) Is(pallet, name string) bool {
+	return e.Pallet == pallet && e.Name == name
+}
+
+// Field returns a named event field, or the positional field at index for events with unnamed fields
+func (e *DecodedEvent) Field(name string, index int) (interface{}, bool) {
+	switch f := e.Fields.(type) {
+	case map[string]interface{}:
+		v, ok := f[name]
+		return v, ok
+	case []interface{}:
+		if index < len(f) {
+			return f[index], true
+		}
+	default:
+		if index == 0 && f != nil {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+/*
+DecodeEvents decodes raw System.Events against the metadata V14 type registry. Unlike types.EventRecords this
+covers every event of the runtime, including the ones go-substrate-rpc-client has no struct for.
+*/
+func DecodeEvents(m *types.Metadata, raw []byte) ([]*DecodedEvent, error) {
+	v, err := decodeStorageValue(m, "System", "Events", raw)
+	if err != nil {
+		return nil, err
+	}
+	records, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected System.Events value %T", v)
+	}
+	events := make([]*DecodedEvent, 0, len(records))
+	for _, r := range records {
+		record, ok := r.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected event record %T", r)
+		}
+		pallet, ok := record["event"].(*Variant)
+		if !ok {
+			return nil, fmt.Errorf("unexpected event %T", record["event"])
+		}
+		ev, ok := pallet.Value.(*Variant)
+		if !ok {
+			return nil, fmt.Errorf("unexpected %s event %T", pallet.Name, pallet.Value)
+		}
+		de := &DecodedEvent{Pallet: pallet.Name, Name: ev.Name, ExtrinsicIndex: -1, Fields: ev.Value}
+		if phase, ok := record["phase"].(*Variant); ok {
+			de.Phase = phase.Name
+			if phase.Name == "ApplyExtrinsic" {
+				de.ExtrinsicIndex = int(toBigInt(phase.Value).Int64())
+			}
+		}
+		events = append(events, de)
+	}
+	return events, nil
+}
+
+// GetBlockEvents returns all events of a block, decoded with the metadata of the block's runtime
+func (c *Client) GetBlockEvents(blockHash types.Hash) ([]*DecodedEvent, error) {
+	meta, err := c.metadataAt(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	key, err := types.CreateStorageKey(meta, "System", "Events")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create storage key:%v", err)
+	}
+	raw, err := c.API.RPC.State.GetStorageRaw(key, blockHash)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query storage: %v", err)
+	}
+	return DecodeEvents(meta, *raw)
+}
+
+// extrinsicEvents returns the events emitted while applying the extrinsic at index
+func extrinsicEvents(events []*DecodedEvent, index int) []*DecodedEvent {
+	var res []*DecodedEvent
+	for _, e := range events {
+		if e.ExtrinsicIndex == index {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+// extrinsicFailure returns the dispatch error of a System.ExtrinsicFailed event among events
+func extrinsicFailure(m *types.Metadata, events []*DecodedEvent) (string, bool) {
+	for _, e := range events {
+		if e.Is("System", "ExtrinsicFailed") {
+			v, _ := e.Field("dispatch_error", 0)
+			return dispatchErrorString(m, v), true
+		}
+	}
+	return "", false
+}
+
+/*
+Describe a decoded DispatchError, module errors are resolved to Pallet.Error through the metadata,
+e.g. "Balances.InsufficientBalance", "Token.FundsUnavailable" or "BadOrigin"
+*/
+func dispatchErrorString(m *types.Metadata, v interface{}) string {
+	de, ok := v.(*Variant)
+	if !ok {
+		return fmt.Sprintf("%v", v)
+	}
+	if de.Name == "Module" {
+		if name, ok := moduleErrorName(m, de.Value); ok {
+			return name
+		}
+	}
+	if inner, ok := de.Value.(*Variant); ok {
+		return de.Name + "." + inner.Name
+	}
+	if de.Value != nil {
+		return fmt.Sprintf("%s(%v)", de.Name, de.Value)
+	}
+	return de.Name
+}
+
+// moduleErrorName resolves a decoded ModuleError {index, error} to Pallet.Error
+func moduleErrorName(m *types.Metadata, v interface{}) (string, bool) {
+	fields, ok := v.(map[string]interface{})
+	if !ok || m == nil || m.Version != 14 {
+		return "", false
+	}
+	index := toBigInt(fields["index"])
+	var errIndex uint8
+	switch e := fields["error"].(type) {
+	case HexBytes:
+		// [u8; 4] since polkadot 0.9.25, the first byte is the error variant
+		if len(e) == 0 {
+			return "", false
+		}
+		errIndex = e[0]
+	default:
+		errIndex = uint8(toBigInt(e).Uint64())
+	}
+	return moduleError(m, uint8(index.Uint64()), errIndex)
+}
+
+func moduleError(m *types.Metadata, index, errIndex uint8) (string, bool) {
+	me, err := m.FindError(types.U8(index), types.U8(errIndex))
+	if err != nil {
+		return "", false
+	}
+	for _, mod := range m.AsMetadataV14.Pallets {
+		if uint8(mod.Index) == index {
+			return string(mod.Name) + "." + me.Name, true
+		}
+	}
+	return me.Name, true
+}
@@ -0,0 +1,106 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/DataHighway-DHX/substrate-go/ss58"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+// DecodedExtrinsic is a raw extrinsic decoded for review, its fields are formatted as block parsing reports them
+type DecodedExtrinsic struct {
+	Txid            string `json:"txid"`
+	Version         uint8  `json:"version"` // extrinsic format version, without the signed bit
+	Signed          bool   `json:"signed"`
+	Signer          string `json:"signer,omitempty"` // SS58 address, decimal index or hex for other address kinds
+	SignerPublicKey string `json:"signer_public_key,omitempty"`
+	SignatureType   string `json:"signature_type,omitempty"` // Ed25519, Sr25519 or Ecdsa
+	Signature       string `json:"signature,omitempty"`
+	Era             string `json:"era,omitempty"` // hex encoded
+	// mortal era period and phase in blocks, zero for an immortal era
+	EraPeriod uint64       `json:"era_period,omitempty"`
+	EraPhase  uint64       `json:"era_phase,omitempty"`
+	Nonce     int64        `json:"nonce"`
+	Tip       string       `json:"tip,omitempty"` // planck
+	Length    int          `json:"length"`
+	Call      *DecodedCall `json:"call"`
+}
+
+/*
+Decode a hex encoded extrinsic, signed or not, against the metadata of the runtime it is meant for.
+Addresses use the System.SS58Prefix of the runtime, 42 when the metadata has none.
+*/
+func DecodeExtrinsic(extHex string, m *types.Metadata) (*DecodedExtrinsic, error) {
+	var ext types.Extrinsic
+	err := types.DecodeFromHex(extHex, &ext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid extrinsic %v", err)
+	}
+	d := &DecodedExtrinsic{
+		Version: ext.Version &^ types.ExtrinsicBitSigned,
+		Signed:  ext.IsSigned(),
+	}
+	d.Txid, err = getTxId(ext)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get txid: %v", err)
+	}
+	d.Length, err = getLength(ext)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get extrinsic length: %v", err)
+	}
+	callBytes, err := types.Encode(ext.Method)
+	if err != nil {
+		return nil, fmt.Errorf("can't encode call %v", err)
+	}
+	d.Call, err = decodeCall(m, callBytes)
+	if err != nil {
+		return nil, fmt.Errorf("can't decode call %v", err)
+	}
+	if !d.Signed {
+		return d, nil
+	}
+
+	network := uint16(42)
+	if prefix, err := decodeConstant(m, "System", "SS58Prefix"); err == nil {
+		network = uint16(toBigInt(prefix).Uint64())
+	}
+	if pub := signerAccountId(ext); pub != nil {
+		d.Signer, err = ss58.EncodeWithNetwork(pub, network)
+		d.SignerPublicKey = fmt.Sprintf("%#x", pub)
+	} else {
+		_, d.Signer, err = FormatMultiAddress(ext.Signature.Signer, network)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to format signer address: %v", err)
+	}
+	sig := ext.Signature.Signature
+	switch {
+	case sig.IsEd25519:
+		d.SignatureType = "Ed25519"
+	case sig.IsSr25519:
+		d.SignatureType = "Sr25519"
+	case sig.IsEcdsa:
+		d.SignatureType = "Ecdsa"
+	}
+	d.Signature, err = getSignature(ext)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get signature: %v", err)
+	}
+	d.Era, err = getEra(ext)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get era: %v", err)
+	}
+	if ext.Signature.Era.IsMortalEra {
+		d.EraPeriod, d.EraPhase = mortalEraPeriod(ext.Signature.Era.AsMortalEra)
+	}
+	d.Nonce = ext.Signature.Nonce.Int64()
+	tip := big.Int(ext.Signature.Tip)
+	d.Tip = tip.String()
+	return d, nil
+}
+
+func (d *DecodedExtrinsic) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
@@ -0,0 +1,166 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/DataHighway-DHX/substrate-go/models"
+	"github.com/DataHighway-DHX/substrate-go/signer"
+	"github.com/DataHighway-DHX/substrate-go/uint128"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+/*
+Estimate the fee accountId pays for call before submitting it. The extrinsic is built with the
+account's era, nonce and tip and a dummy signature of its crypto type, so its length fee is exact.
+The NonceManager is not consulted, estimating never uses up a nonce.
+*/
+func (c *Client) EstimateFee(call types.Call, accountId []byte, crypto signer.CryptoType, tip uint128.Uint128) (*models.FeeEstimate, error) {
+	ext, err := c.dummySigned(call, accountId, crypto, tip)
+	if err != nil {
+		return nil, err
+	}
+	return c.QueryFee(ext)
+}
+
+// EstimateTransferFee estimates the fee of a transfer from accountId
+func (c *Client) EstimateTransferFee(t Transfer, accountId []byte, crypto signer.CryptoType, tip uint128.Uint128) (*models.FeeEstimate, error) {
+	err := c.checkRuntimeVersion()
+	if err != nil {
+		return nil, err
+	}
+	ca, err := c.NewTransferCall(t)
+	if err != nil {
+		return nil, err
+	}
+	return c.EstimateFee(ca, accountId, crypto, tip)
+}
+
+func (c *Client) dummySigned(call types.Call, accountId []byte, crypto signer.CryptoType, tip uint128.Uint128) (ext types.Extrinsic, err error) {
+	gHash, err := c.GetGenesisHash()
+	if err != nil {
+		return ext, err
+	}
+	era, _, err := c.getEra(*gHash)
+	if err != nil {
+		return ext, err
+	}
+	ai, err := c.GetAccount(accountId)
+	if err != nil {
+		return ext, err
+	}
+	p := &types.ExtrinsicPayloadV4{ExtrinsicPayloadV3: types.ExtrinsicPayloadV3{
+		Era:   era,
+		Nonce: types.NewUCompactFromUInt(uint64(ai.Nonce)),
+		Tip:   types.NewUCompact(tip.Big()),
+	}}
+	return assembleExtrinsic(call, p, accountId, crypto, make([]byte, signatureLength(crypto)))
+}
+
+// QueryFee returns the fee of a signed extrinsic from payment_queryInfo and payment_queryFeeDetails
+func (c *Client) QueryFee(ext types.Extrinsic) (*models.FeeEstimate, error) {
+	length, err := getLength(ext)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get extrinsic length: %v", err)
+	}
+	var rawInfo json.RawMessage
+	err = c.API.Client.Call(&rawInfo, "payment_queryInfo", ext)
+	if err != nil {
+		return nil, fmt.Errorf("get payment info error: %v", err)
+	}
+	info, err := jsonObject(rawInfo)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payment info: %v", err)
+	}
+	partialFee, err := rpcBigInt(info["partialFee"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid partialFee: %v", err)
+	}
+	tip := big.Int(ext.Signature.Tip)
+	res := &models.FeeEstimate{
+		Length:     length,
+		PartialFee: c.NewAmount(partialFee),
+		Tip:        c.NewAmount(&tip),
+		Total:      c.NewAmount(new(big.Int).Add(partialFee, &tip)),
+	}
+	res.Class, _ = info["class"].(string)
+	res.Class = strings.ToLower(res.Class)
+	res.Weight, res.ProofSize, err = parseWeight(info["weight"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid weight: %v", err)
+	}
+
+	var rawDetails json.RawMessage
+	err = c.API.Client.Call(&rawDetails, "payment_queryFeeDetails", ext)
+	if err != nil {
+		return nil, fmt.Errorf("get fee details error: %v", err)
+	}
+	details, err := jsonObject(rawDetails)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fee details: %v", err)
+	}
+	// inclusionFee is null for unsigned and fee-less extrinsics
+	if inclusion, ok := details["inclusionFee"].(map[string]interface{}); ok {
+		fees := []**models.Amount{&res.BaseFee, &res.LenFee, &res.AdjustedWeightFee}
+		for i, name := range []string{"baseFee", "lenFee", "adjustedWeightFee"} {
+			n, err := rpcBigInt(inclusion[name])
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %v", name, err)
+			}
+			*fees[i] = c.NewAmount(n)
+		}
+	}
+	return res, nil
+}
+
+func jsonObject(data []byte) (map[string]interface{}, error) {
+	v, err := decodeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected an object, got %s", data)
+	}
+	return m, nil
+}
+
+// rpcBigInt parses a number the node returned as a JSON number, decimal string or hex string
+func rpcBigInt(v interface{}) (*big.Int, error) {
+	if v == nil {
+		return nil, fmt.Errorf("missing")
+	}
+	return toInteger(v)
+}
+
+// parseWeight accepts a one dimensional weight or a {refTime, proofSize} weight
+func parseWeight(v interface{}) (refTime, proofSize uint64, err error) {
+	if m, ok := v.(map[string]interface{}); ok {
+		for _, k := range []string{"refTime", "ref_time"} {
+			if r, ok := m[k]; ok {
+				n, err := rpcBigInt(r)
+				if err != nil {
+					return 0, 0, err
+				}
+				refTime = n.Uint64()
+			}
+		}
+		for _, k := range []string{"proofSize", "proof_size"} {
+			if p, ok := m[k]; ok {
+				n, err := rpcBigInt(p)
+				if err != nil {
+					return 0, 0, err
+				}
+				proofSize = n.Uint64()
+			}
+		}
+		return refTime, proofSize, nil
+	}
+	n, err := rpcBigInt(v)
+	if err != nil {
+		return 0, 0, err
+	}
+	return n.Uint64(), 0, nil
+}
@@ -0,0 +1,236 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/DataHighway-DHX/substrate-go/models"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+var ErrExtrinsicNotFound = errors.New("extrinsic not found")
+
+type txLocation struct {
+	blockHash types.Hash
+	height    int64
+	index     int
+}
+
+// TxIndex is an in-memory index from extrinsic hash to its position, keeping the most recent entries
+type TxIndex struct {
+	mu      sync.RWMutex
+	size    int
+	order   []string
+	entries map[string]txLocation
+}
+
+func NewTxIndex(size int) *TxIndex {
+	return &TxIndex{
+		size:    size,
+		entries: make(map[string]txLocation),
+	}
+}
+
+func (ix *TxIndex) AddBlock(blockHash types.Hash, height int64, extrinsics []types.Extrinsic) {
+	for i, ext := range extrinsics {
+		txid, err := getTxId(ext)
+		if err != nil {
+			continue
+		}
+		ix.add(txid, txLocation{blockHash: blockHash, height: height, index: i})
+	}
+}
+
+func (ix *TxIndex) add(txid string, loc txLocation) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if _, ok := ix.entries[txid]; !ok {
+		ix.order = append(ix.order, txid)
+	}
+	ix.entries[txid] = loc
+	for ix.size > 0 && len(ix.order) > ix.size {
+		delete(ix.entries, ix.order[0])
+		ix.order = ix.order[1:]
+	}
+}
+
+func (ix *TxIndex) get(txid string) (txLocation, bool) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	loc, ok := ix.entries[txid]
+	return loc, ok
+}
+
+func (ix *TxIndex) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.entries)
+}
+
+/*
+Find the block that included an extrinsic. Substrate keeps no transaction index, so the
+TxIndex is checked first and then the latest searchWindow blocks are scanned from the head down.
+Only the scanned blocks that are finalized are indexed, a block that can still be retracted is not.
+*/
+func (c *Client) FindExtrinsic(ctx context.Context, txHash types.Hash, searchWindow int) (*models.ExtrinsicLocation, error) {
+	txid := txHash.Hex()
+	if c.TxIndex != nil {
+		if loc, ok := c.TxIndex.get(txid); ok {
+			block, err := c.API.RPC.Chain.GetBlock(loc.blockHash)
+			if err != nil {
+				return nil, fmt.Errorf("get block error: %v", err)
+			}
+			return c.extrinsicLocation(txid, loc, block)
+		}
+	}
+
+	head, err := c.API.RPC.Chain.GetHeaderLatest()
+	if err != nil {
+		return nil, fmt.Errorf("get latest header error: %v", err)
+	}
+	_, finalized, err := c.finalizedHead()
+	if err != nil {
+		return nil, err
+	}
+	for n := int64(head.Number); n >= 0 && n > int64(head.Number)-int64(searchWindow); n-- {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		hash, err := c.API.RPC.Chain.GetBlockHash(uint64(n))
+		if err != nil {
+			return nil, fmt.Errorf("get block hash error:%v,height:%d", err, n)
+		}
+		block, err := c.API.RPC.Chain.GetBlock(hash)
+		if err != nil {
+			return nil, fmt.Errorf("get block error: %v", err)
+		}
+		if c.TxIndex != nil && n <= finalized {
+			c.TxIndex.AddBlock(hash, n, block.Block.Extrinsics)
+		}
+		for i, ext := range block.Block.Extrinsics {
+			id, err := getTxId(ext)
+			if err != nil {
+				return nil, err
+			}
+			if id == txid {
+				return c.extrinsicLocation(txid, txLocation{blockHash: hash, height: n, index: i}, block)
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: %s in the latest %d blocks", ErrExtrinsicNotFound, txid, searchWindow)
+}
+
+func (c *Client) extrinsicLocation(txid string, loc txLocation, block *types.SignedBlock) (*models.ExtrinsicLocation, error) {
+	if loc.index >= len(block.Block.Extrinsics) {
+		return nil, fmt.Errorf("unable to access extrinsics by index: %d", loc.index)
+	}
+	meta, err := c.metadataAt(loc.blockHash)
+	if err != nil {
+		return nil, err
+	}
+	events, err := c.GetBlockEvents(loc.blockHash)
+	if err != nil {
+		return nil, err
+	}
+	status := "success"
+	if _, failed := extrinsicFailure(meta, extrinsicEvents(events, loc.index)); failed {
+		status = "fail"
+	}
+	res := &models.ExtrinsicLocation{
+		Txid:           txid,
+		BlockHash:      loc.blockHash.Hex(),
+		Height:         loc.height,
+		ExtrinsicIndex: loc.index,
+		Status:         status,
+	}
+	ext := block.Block.Extrinsics[loc.index]
+	if ext.IsSigned() {
+		res.Fee, err = c.getPartialFee(ext, block.Block.Header.ParentHash.Hex())
+		if err != nil {
+			return nil, fmt.Errorf("unable to get fee: %v", err)
+		}
+	}
+	return res, nil
+}
+
+/*
+IndexNewBlocks feeds every finalized block into the TxIndex until ctx is done. Finalized heads can
+skip blocks, the blocks in between are read by number so that none is missed, and blocks that
+are not finalized are never indexed so no retracted block ends up in the index.
+*/
+func (c *Client) IndexNewBlocks(ctx context.Context) error {
+	if c.TxIndex == nil {
+		return errors.New("tx index is not enabled")
+	}
+	sub, err := c.API.RPC.Chain.SubscribeFinalizedHeads()
+	if err != nil {
+		return fmt.Errorf("subscribe finalized heads error: %v", err)
+	}
+	defer sub.Unsubscribe()
+	next := int64(-1)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-sub.Err():
+			return fmt.Errorf("finalized heads subscription error: %v", err)
+		case head := <-sub.Chan():
+			if next < 0 {
+				next = int64(head.Number)
+			}
+			for ; next <= int64(head.Number); next++ {
+				err = c.indexBlock(next)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// finalizedHead returns the hash and height of the latest finalized block
+func (c *Client) finalizedHead() (types.Hash, int64, error) {
+	hash, err := c.API.RPC.Chain.GetFinalizedHead()
+	if err != nil {
+		return hash, 0, fmt.Errorf("can't get finalized head %v", err)
+	}
+	header, err := c.API.RPC.Chain.GetHeader(hash)
+	if err != nil {
+		return hash, 0, fmt.Errorf("can't get finalized header %v", err)
+	}
+	return hash, int64(header.Number), nil
+}
+
+// indexFinalizedBlock adds a block to the TxIndex when it is finalized and on the canonical chain
+func (c *Client) indexFinalizedBlock(blockHash types.Hash, height int64, extrinsics []types.Extrinsic) error {
+	_, finalized, err := c.finalizedHead()
+	if err != nil {
+		return err
+	}
+	if height > finalized {
+		return nil
+	}
+	canonical, err := c.API.RPC.Chain.GetBlockHash(uint64(height))
+	if err != nil {
+		return fmt.Errorf("get block hash error:%v,height:%d", err, height)
+	}
+	if canonical == blockHash {
+		c.TxIndex.AddBlock(blockHash, height, extrinsics)
+	}
+	return nil
+}
+
+func (c *Client) indexBlock(height int64) error {
+	hash, err := c.API.RPC.Chain.GetBlockHash(uint64(height))
+	if err != nil {
+		return fmt.Errorf("get block hash error:%v,height:%d", err, height)
+	}
+	block, err := c.API.RPC.Chain.GetBlock(hash)
+	if err != nil {
+		return fmt.Errorf("get block error: %v", err)
+	}
+	c.TxIndex.AddBlock(hash, height, block.Block.Extrinsics)
+	return nil
+}
@@ -0,0 +1,190 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/DataHighway-DHX/substrate-go/uint128"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Multisig is a multisig account of the Multisig pallet
+type Multisig struct {
+	Signatories [][]byte // account ids
+	Threshold   uint16
+}
+
+func (ms Multisig) AccountId() []byte {
+	return MultisigAccountId(ms.Signatories, ms.Threshold)
+}
+
+// otherSignatories returns the signatories except self, sorted as the pallet requires
+func (ms Multisig) otherSignatories(self []byte) ([]interface{}, error) {
+	var others [][]byte
+	found := false
+	for _, s := range ms.Signatories {
+		if !found && bytes.Equal(s, self) {
+			found = true
+			continue
+		}
+		others = append(others, s)
+	}
+	if !found {
+		return nil, fmt.Errorf("%s is not a signatory of the multisig", types.HexEncodeToString(self))
+	}
+	sort.Slice(others, func(i, j int) bool {
+		return bytes.Compare(others[i], others[j]) < 0
+	})
+	list := make([]interface{}, len(others))
+	for i, s := range others {
+		list[i] = s
+	}
+	return list, nil
+}
+
+// Timepoint is the block height and extrinsic index of the first approval of a multisig operation
+type Timepoint struct {
+	Height uint32 `json:"height"`
+	Index  uint32 `json:"index"`
+}
+
+func (tp *Timepoint) arg() interface{} {
+	if tp == nil {
+		return nil
+	}
+	return map[string]interface{}{"height": tp.Height, "index": tp.Index}
+}
+
+// Weight limits the weight of a dispatched call, ProofSize is ignored by runtimes with one dimensional weights
+type Weight struct {
+	RefTime   uint64
+	ProofSize uint64
+}
+
+// MultisigOperation is an open operation from Multisig.Multisigs
+type MultisigOperation struct {
+	When      Timepoint       `json:"when"`
+	Deposit   uint128.Uint128 `json:"deposit"`
+	Depositor string          `json:"depositor"` // hex account id
+	Approvals []string        `json:"approvals"` // hex account ids
+}
+
+// CallHash is the blake2_256 hash of an encoded call, which approve_as_multi and cancel_as_multi refer to
+func CallHash(ca types.Call) ([32]byte, error) {
+	b, err := types.Encode(ca)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("can't encode call %v", err)
+	}
+	return blake2b.Sum256(b), nil
+}
+
+/*
+Read the open operation of multisig for callHash from Multisig.Multisigs, nil when there is none.
+Its When is the timepoint every approval after the first one and the cancellation must pass.
+*/
+func (c *Client) GetMultisig(multisig []byte, callHash [32]byte) (*MultisigOperation, error) {
+	meta := c.metadata()
+	key, err := types.CreateStorageKey(meta, "Multisig", "Multisigs", multisig, callHash[:])
+	if err != nil {
+		return nil, fmt.Errorf("can't create storage key %v", err)
+	}
+	raw, err := c.API.RPC.State.GetStorageRawLatest(key)
+	if err != nil {
+		return nil, fmt.Errorf("can't get storage for multisig %v", err)
+	}
+	if raw == nil || len(*raw) == 0 {
+		return nil, nil
+	}
+	v, err := decodeStorageValue(meta, "Multisig", "Multisigs", *raw)
+	if err != nil {
+		return nil, err
+	}
+	fields, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected multisig %v", v)
+	}
+	when, _ := fields["when"].(map[string]interface{})
+	op := &MultisigOperation{
+		When: Timepoint{
+			Height: uint32(toBigInt(when["height"]).Uint64()),
+			Index:  uint32(toBigInt(when["index"]).Uint64()),
+		},
+		Deposit: uint128.FromBig(toBigInt(fields["deposit"])),
+	}
+	if depositor := accountIdFromValue(fields["depositor"]); depositor != nil {
+		op.Depositor = types.HexEncodeToString(depositor)
+	}
+	approvals, _ := fields["approvals"].([]interface{})
+	for _, a := range approvals {
+		if id := accountIdFromValue(a); id != nil {
+			op.Approvals = append(op.Approvals, types.HexEncodeToString(id))
+		}
+	}
+	return op, nil
+}
+
+/*
+Multisig.as_multi of self, a signatory of ms, approving and dispatching ca once the threshold is
+reached. timepoint is nil for the first approval and When of the open operation otherwise.
+*/
+func (c *Client) NewAsMulti(ms Multisig, self []byte, ca types.Call, timepoint *Timepoint, maxWeight Weight) (types.Call, error) {
+	return c.newMultisigCall("Multisig.as_multi", ms, self, map[string]interface{}{
+		"maybe_timepoint": timepoint.arg(),
+		"call":            ca,
+	}, maxWeight)
+}
+
+// Multisig.approve_as_multi of self approving the call with callHash without dispatching it
+func (c *Client) NewApproveAsMulti(ms Multisig, self []byte, callHash [32]byte, timepoint *Timepoint, maxWeight Weight) (types.Call, error) {
+	return c.newMultisigCall("Multisig.approve_as_multi", ms, self, map[string]interface{}{
+		"maybe_timepoint": timepoint.arg(),
+		"call_hash":       callHash[:],
+	}, maxWeight)
+}
+
+// Multisig.cancel_as_multi of self, the depositor of the operation, releasing its deposit
+func (c *Client) NewCancelAsMulti(ms Multisig, self []byte, callHash [32]byte, timepoint Timepoint) (types.Call, error) {
+	return c.newMultisigCall("Multisig.cancel_as_multi", ms, self, map[string]interface{}{
+		"timepoint": timepoint.arg(),
+		"call_hash": callHash[:],
+	}, Weight{})
+}
+
+// newMultisigCall fills the fields that differ between runtime versions, store_call and the kind of max_weight
+func (c *Client) newMultisigCall(name string, ms Multisig, self []byte, args map[string]interface{}, maxWeight Weight) (types.Call, error) {
+	others, err := ms.otherSignatories(self)
+	if err != nil {
+		return types.Call{}, err
+	}
+	args["threshold"] = ms.Threshold
+	args["other_signatories"] = others
+
+	meta, rv := c.runtime()
+	e, err := newTypeEncoder(meta)
+	if err != nil {
+		return types.Call{}, err
+	}
+	_, fields, err := e.findCall(name)
+	if err != nil {
+		return types.Call{}, fmt.Errorf("%w (spec %s v%d)", err, rv.SpecName, rv.SpecVersion)
+	}
+	for _, f := range fields {
+		switch f.Name {
+		case "store_call":
+			args["store_call"] = false
+		case "max_weight":
+			t, err := e.lookup(f.Type.Int64())
+			if err != nil {
+				return types.Call{}, err
+			}
+			if t.Def.IsPrimitive {
+				args["max_weight"] = maxWeight.RefTime
+			} else {
+				args["max_weight"] = map[string]interface{}{"ref_time": maxWeight.RefTime, "proof_size": maxWeight.ProofSize}
+			}
+		}
+	}
+	return NewCallWithArgs(meta, name, args)
+}
@@ -0,0 +1,171 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+// NonceSource returns the next nonce of an account, e.g. Client.AccountNextIndex
+type NonceSource func(accountId []byte) (uint64, error)
+
+/*
+NonceManager hands out nonces locally so that several transactions from one account can be
+in flight at once. Each account is seeded from the NonceSource on first use.
+Nonces of submissions that never reached the pool are returned with Release and handed out
+again before new ones; rejections that mean the local view is wrong trigger a Resync.
+*/
+type NonceManager struct {
+	mu       sync.Mutex
+	source   NonceSource
+	accounts map[string]*accountNonces
+}
+
+type accountNonces struct {
+	mu       sync.Mutex
+	synced   bool
+	next     uint64
+	released []uint64
+}
+
+func NewNonceManager(source NonceSource) *NonceManager {
+	return &NonceManager{
+		source:   source,
+		accounts: make(map[string]*accountNonces),
+	}
+}
+
+func (nm *NonceManager) account(accountId []byte) *accountNonces {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	key := string(accountId)
+	a, ok := nm.accounts[key]
+	if !ok {
+		a = new(accountNonces)
+		nm.accounts[key] = a
+	}
+	return a
+}
+
+// Next returns the nonce to sign the next transaction of accountId with
+func (nm *NonceManager) Next(accountId []byte) (uint64, error) {
+	a := nm.account(accountId)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.synced {
+		next, err := nm.source(accountId)
+		if err != nil {
+			return 0, fmt.Errorf("can't get account next index %v", err)
+		}
+		a.next, a.synced = next, true
+	}
+	if len(a.released) > 0 {
+		n := a.released[0]
+		a.released = a.released[1:]
+		return n, nil
+	}
+	n := a.next
+	a.next++
+	return n, nil
+}
+
+// Release returns a nonce whose transaction was not accepted into the pool
+func (nm *NonceManager) Release(accountId []byte, nonce uint64) {
+	a := nm.account(accountId)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.synced || nonce >= a.next {
+		return
+	}
+	if nonce == a.next-1 {
+		a.next--
+		return
+	}
+	for _, n := range a.released {
+		if n == nonce {
+			return
+		}
+	}
+	a.released = append(a.released, nonce)
+	sort.Slice(a.released, func(i, j int) bool { return a.released[i] < a.released[j] })
+}
+
+// Resync drops the local state of accountId, the next call to Next seeds it again
+func (nm *NonceManager) Resync(accountId []byte) {
+	a := nm.account(accountId)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.synced = false
+	a.released = nil
+}
+
+/*
+Failed records a failed submission of the transaction signed with nonce. Pool rejections that
+point at a wrong nonce (stale, future, priority too low, already imported, invalid) resync the
+account, any other error releases the nonce for reuse.
+*/
+func (nm *NonceManager) Failed(accountId []byte, nonce uint64, err error) {
+	if nonceOutOfSync(err) {
+		nm.Resync(accountId)
+		return
+	}
+	nm.Release(accountId, nonce)
+}
+
+func nonceOutOfSync(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"stale", "outdated", "future", "priority is too low", "already imported", "invalid transaction", ErrTxInvalid.Error(), ErrTxUsurped.Error()} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// AccountNextIndex returns the next nonce of an account, counting its transactions in the pool
+func (c *Client) AccountNextIndex(accountId []byte) (uint64, error) {
+	address, err := c.SS58(accountId)
+	if err != nil {
+		return 0, err
+	}
+	var next uint64
+	err = c.API.Client.Call(&next, "system_accountNextIndex", address)
+	if err != nil {
+		return 0, fmt.Errorf("system_accountNextIndex error: %v", err)
+	}
+	return next, nil
+}
+
+// nextNonce returns the nonce for signer, from the NonceManager when one is set
+func (c *Client) nextNonce(signer []byte) (uint64, error) {
+	if c.Nonces != nil {
+		return c.Nonces.Next(signer)
+	}
+	ai, err := c.GetAccount(signer)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(ai.Nonce), nil
+}
+
+// submitFailed hands the nonce of a signed extrinsic that failed to submit back to the NonceManager
+func (c *Client) submitFailed(ext types.Extrinsic, err error) {
+	signer := signerAccountId(ext)
+	if c.Nonces == nil || signer == nil {
+		return
+	}
+	c.Nonces.Failed(signer, uint64(ext.Signature.Nonce.Int64()), err)
+}
+
+// releaseNonce returns the nonce of signature options that were not used to sign
+func (c *Client) releaseNonce(signer []byte, so types.SignatureOptions) {
+	if c.Nonces != nil {
+		c.Nonces.Release(signer, uint64(so.Nonce.Int64()))
+	}
+}
@@ -0,0 +1,363 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/DataHighway-DHX/substrate-go/signer"
+	"github.com/DataHighway-DHX/substrate-go/uint128"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"golang.org/x/crypto/blake2b"
+)
+
+/*
+Offline signing in three stages:
+ 1. BuildUnsignedTx, online host without keys: call, nonce, era and hashes to sign
+ 2. SignUnsignedTx, air-gapped host with the key and no network. Hardware signers sign
+    SigningPayload and the signature is combined with AssembleSignedTx
+ 3. SubmitSignedTx, online host broadcasts the signed extrinsic
+UnsignedTx and SignedTx serialise to JSON so they can be carried between hosts.
+*/
+
+type UnsignedTx struct {
+	Signer             string `json:"signer"`  // hex account id
+	Crypto             string `json:"crypto"`  // ed25519, sr25519 or ecdsa
+	Address            string `json:"address"` // SS58 address of the signer, informational
+	Call               string `json:"call"`    // hex encoded call
+	Era                string `json:"era"`     // hex encoded era
+	Nonce              uint64 `json:"nonce"`
+	Tip                string `json:"tip"` // planck
+	SpecVersion        uint32 `json:"spec_version"`
+	TransactionVersion uint32 `json:"transaction_version"`
+	GenesisHash        string `json:"genesis_hash"`
+	BlockHash          string `json:"block_hash"`
+}
+
+type SignedTx struct {
+	Extrinsic string `json:"extrinsic"` // hex encoded signed extrinsic
+	Hash      string `json:"hash"`
+}
+
+// BuildUnsignedTx prepares call for signing by accountId with a key of type crypto, see signer.AccountId
+func (c *Client) BuildUnsignedTx(accountId []byte, crypto signer.CryptoType, call types.Call, tip uint128.Uint128) (*UnsignedTx, error) {
+	if crypto != signer.Ed25519 && crypto != signer.Sr25519 && crypto != signer.Ecdsa {
+		return nil, fmt.Errorf("unsupported crypto type %s", crypto)
+	}
+	so, err := c.GetSignatureOptions(accountId, tip)
+	if err != nil {
+		return nil, fmt.Errorf("can't get signature options %v", err)
+	}
+	callHex, err := types.EncodeToHex(call)
+	if err != nil {
+		return nil, fmt.Errorf("can't encode call %v", err)
+	}
+	eraHex, err := types.EncodeToHex(so.Era)
+	if err != nil {
+		return nil, fmt.Errorf("can't encode era %v", err)
+	}
+	address, err := c.SS58(accountId)
+	if err != nil {
+		return nil, err
+	}
+	nonce := big.Int(so.Nonce)
+	tipValue := big.Int(so.Tip)
+	return &UnsignedTx{
+		Signer:             types.HexEncodeToString(accountId),
+		Crypto:             crypto.String(),
+		Address:            address,
+		Call:               callHex,
+		Era:                eraHex,
+		Nonce:              nonce.Uint64(),
+		Tip:                tipValue.String(),
+		SpecVersion:        uint32(so.SpecVersion),
+		TransactionVersion: uint32(so.TransactionVersion),
+		GenesisHash:        so.GenesisHash.Hex(),
+		BlockHash:          so.BlockHash.Hex(),
+	}, nil
+}
+
+// BuildUnsignedTransfer prepares a transfer for offline signing
+func (c *Client) BuildUnsignedTransfer(accountId []byte, crypto signer.CryptoType, t Transfer, tip uint128.Uint128) (*UnsignedTx, error) {
+	err := c.checkRuntimeVersion()
+	if err != nil {
+		return nil, err
+	}
+	ca, err := c.NewTransferCall(t)
+	if err != nil {
+		return nil, err
+	}
+	return c.BuildUnsignedTx(accountId, crypto, ca, tip)
+}
+
+func UnmarshalUnsignedTx(data []byte) (*UnsignedTx, error) {
+	var tx UnsignedTx
+	err := json.Unmarshal(data, &tx)
+	if err != nil {
+		return nil, fmt.Errorf("json unmarshal unsigned tx error: %v", err)
+	}
+	return &tx, nil
+}
+
+func (tx *UnsignedTx) Marshal() ([]byte, error) {
+	return json.MarshalIndent(tx, "", "  ")
+}
+
+func (tx *UnsignedTx) method() (types.Call, error) {
+	return decodeCallHex(tx.Call)
+}
+
+// decodeCallHex splits a hex encoded call into its call index and arguments
+func decodeCallHex(call string) (types.Call, error) {
+	b, err := types.HexDecodeString(call)
+	if err != nil {
+		return types.Call{}, fmt.Errorf("invalid call hex %v", err)
+	}
+	if len(b) < 2 {
+		return types.Call{}, fmt.Errorf("call is too short")
+	}
+	return types.Call{
+		CallIndex: types.CallIndex{SectionIndex: b[0], MethodIndex: b[1]},
+		Args:      b[2:],
+	}, nil
+}
+
+func (tx *UnsignedTx) cryptoType() (signer.CryptoType, error) {
+	if tx.Crypto == "" {
+		return 0, fmt.Errorf("unsigned tx has no crypto type")
+	}
+	return signer.ParseCryptoType(tx.Crypto)
+}
+
+func (tx *UnsignedTx) SignatureOptions() (so types.SignatureOptions, err error) {
+	err = types.DecodeFromHex(tx.Era, &so.Era)
+	if err != nil {
+		return so, fmt.Errorf("invalid era %v", err)
+	}
+	tip, ok := new(big.Int).SetString(tx.Tip, 10)
+	if !ok {
+		return so, fmt.Errorf("invalid tip %s", tx.Tip)
+	}
+	so.GenesisHash, err = types.NewHashFromHexString(tx.GenesisHash)
+	if err != nil {
+		return so, fmt.Errorf("invalid genesis hash %v", err)
+	}
+	so.BlockHash, err = types.NewHashFromHexString(tx.BlockHash)
+	if err != nil {
+		return so, fmt.Errorf("invalid block hash %v", err)
+	}
+	so.Nonce = types.NewUCompactFromUInt(tx.Nonce)
+	so.Tip = types.NewUCompact(tip)
+	so.SpecVersion = types.U32(tx.SpecVersion)
+	so.TransactionVersion = types.U32(tx.TransactionVersion)
+	return so, nil
+}
+
+// payload returns the signing payload of the transaction
+func (tx *UnsignedTx) payload() (*types.ExtrinsicPayloadV4, error) {
+	ca, err := tx.method()
+	if err != nil {
+		return nil, err
+	}
+	so, err := tx.SignatureOptions()
+	if err != nil {
+		return nil, err
+	}
+	return newPayload(ca, so)
+}
+
+func newPayload(ca types.Call, so types.SignatureOptions) (*types.ExtrinsicPayloadV4, error) {
+	mb, err := types.Encode(ca)
+	if err != nil {
+		return nil, fmt.Errorf("can't encode call %v", err)
+	}
+	era := so.Era
+	if !era.IsMortalEra {
+		era = types.ExtrinsicEra{IsImmortalEra: true}
+	}
+	return &types.ExtrinsicPayloadV4{
+		ExtrinsicPayloadV3: types.ExtrinsicPayloadV3{
+			Method:      mb,
+			Era:         era,
+			Nonce:       so.Nonce,
+			Tip:         so.Tip,
+			SpecVersion: so.SpecVersion,
+			GenesisHash: so.GenesisHash,
+			BlockHash:   so.BlockHash,
+		},
+		TransactionVersion: so.TransactionVersion,
+	}, nil
+}
+
+// SigningPayload returns the bytes the signer signs, e.g. to show them on a hardware device
+func (tx *UnsignedTx) SigningPayload() ([]byte, error) {
+	p, err := tx.payload()
+	if err != nil {
+		return nil, err
+	}
+	return signingPayload(p)
+}
+
+// signingPayload encodes p, payloads longer than 256 bytes are signed by their blake2_256 hash
+func signingPayload(p *types.ExtrinsicPayloadV4) ([]byte, error) {
+	b, err := types.Encode(p)
+	if err != nil {
+		return nil, fmt.Errorf("can't encode signing payload %v", err)
+	}
+	if len(b) > 256 {
+		h := blake2b.Sum256(b)
+		return h[:], nil
+	}
+	return b, nil
+}
+
+/*
+Sign an UnsignedTx. Runs without network access when s holds the key, s must be the signer
+the transaction was built for.
+*/
+func SignUnsignedTx(tx *UnsignedTx, s signer.Signer) (*SignedTx, error) {
+	pub, err := types.HexDecodeString(tx.Signer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signer %v", err)
+	}
+	if !bytes.Equal(pub, signer.AccountId(s)) {
+		return nil, fmt.Errorf("key does not belong to signer %s", tx.Signer)
+	}
+	crypto, err := tx.cryptoType()
+	if err != nil {
+		return nil, err
+	}
+	if crypto != s.CryptoType() {
+		return nil, fmt.Errorf("transaction is for a %s key, got %s", crypto, s.CryptoType())
+	}
+	payload, err := tx.SigningPayload()
+	if err != nil {
+		return nil, err
+	}
+	sig, err := s.Sign(payload)
+	if err != nil {
+		return nil, fmt.Errorf("can't sign extrinsic %v", err)
+	}
+	return assembleSignedTx(tx, s.CryptoType(), sig)
+}
+
+// AssembleSignedTx combines an UnsignedTx with the signature of its SigningPayload, of the type named by Crypto
+func AssembleSignedTx(tx *UnsignedTx, sig []byte) (*SignedTx, error) {
+	crypto, err := tx.cryptoType()
+	if err != nil {
+		return nil, err
+	}
+	return assembleSignedTx(tx, crypto, sig)
+}
+
+func assembleSignedTx(tx *UnsignedTx, crypto signer.CryptoType, sig []byte) (*SignedTx, error) {
+	pub, err := types.HexDecodeString(tx.Signer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signer %v", err)
+	}
+	ca, err := tx.method()
+	if err != nil {
+		return nil, err
+	}
+	p, err := tx.payload()
+	if err != nil {
+		return nil, err
+	}
+	ext, err := assembleExtrinsic(ca, p, pub, crypto, sig)
+	if err != nil {
+		return nil, err
+	}
+	return newSignedTx(ext)
+}
+
+// signatureLength is 65 bytes for a recoverable ecdsa signature and 64 for ed25519 and sr25519
+func signatureLength(crypto signer.CryptoType) int {
+	if crypto == signer.Ecdsa {
+		return 65
+	}
+	return 64
+}
+
+// assembleExtrinsic builds the signed extrinsic of ca from its signing payload and signature
+func assembleExtrinsic(ca types.Call, p *types.ExtrinsicPayloadV4, accountId []byte, crypto signer.CryptoType, sig []byte) (types.Extrinsic, error) {
+	if len(sig) != signatureLength(crypto) {
+		return types.Extrinsic{}, fmt.Errorf("invalid %s signature length %d", crypto, len(sig))
+	}
+	var ms types.MultiSignature
+	switch crypto {
+	case signer.Ed25519:
+		ms = types.MultiSignature{IsEd25519: true, AsEd25519: types.NewSignature(sig)}
+	case signer.Sr25519:
+		ms = types.MultiSignature{IsSr25519: true, AsSr25519: types.NewSignature(sig)}
+	case signer.Ecdsa:
+		ms = types.MultiSignature{IsEcdsa: true, AsEcdsa: types.NewEcdsaSignature(sig)}
+	default:
+		return types.Extrinsic{}, fmt.Errorf("unsupported crypto type %s", crypto)
+	}
+	if len(accountId) != 32 {
+		return types.Extrinsic{}, fmt.Errorf("invalid signer length %d", len(accountId))
+	}
+
+	ext := types.NewExtrinsic(ca)
+	ext.Signature = types.ExtrinsicSignatureV4{
+		Signer:    types.NewMultiAddressFromAccountID(accountId),
+		Signature: ms,
+		Era:       p.Era,
+		Nonce:     p.Nonce,
+		Tip:       p.Tip,
+	}
+	ext.Version |= types.ExtrinsicBitSigned
+	return ext, nil
+}
+
+func newSignedTx(ext types.Extrinsic) (*SignedTx, error) {
+	extHex, err := types.EncodeToHex(ext)
+	if err != nil {
+		return nil, fmt.Errorf("can't encode extrinsic %v", err)
+	}
+	hash, err := getTxId(ext)
+	if err != nil {
+		return nil, err
+	}
+	return &SignedTx{Extrinsic: extHex, Hash: hash}, nil
+}
+
+func UnmarshalSignedTx(data []byte) (*SignedTx, error) {
+	var tx SignedTx
+	err := json.Unmarshal(data, &tx)
+	if err != nil {
+		return nil, fmt.Errorf("json unmarshal signed tx error: %v", err)
+	}
+	return &tx, nil
+}
+
+func (tx *SignedTx) Marshal() ([]byte, error) {
+	return json.MarshalIndent(tx, "", "  ")
+}
+
+func (tx *SignedTx) Decode() (types.Extrinsic, error) {
+	var ext types.Extrinsic
+	err := types.DecodeFromHex(tx.Extrinsic, &ext)
+	if err != nil {
+		return ext, fmt.Errorf("invalid extrinsic %v", err)
+	}
+	if !ext.IsSigned() {
+		return ext, fmt.Errorf("extrinsic is not signed")
+	}
+	return ext, nil
+}
+
+// SubmitSignedTx broadcasts an extrinsic signed offline
+func (c *Client) SubmitSignedTx(tx *SignedTx) (txHash types.Hash, err error) {
+	ext, err := tx.Decode()
+	if err != nil {
+		return txHash, err
+	}
+	txHash, err = c.API.RPC.Author.SubmitExtrinsic(ext)
+	if err != nil {
+		c.submitFailed(ext, err)
+		return txHash, fmt.Errorf("can't SubmitExtrinsic %v", err)
+	}
+	return
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/DataHighway-DHX/substrate-go/signer"
+	"github.com/DataHighway-DHX/substrate-go/uint128"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+// ProxyDefinition is a delegate allowed to dispatch calls for an account
+type ProxyDefinition struct {
+	Delegate  string `json:"delegate"`   // hex account id
+	ProxyType string `json:"proxy_type"` // e.g. Any, NonTransfer or Staking, runtime specific
+	Delay     uint32 `json:"delay"`      // blocks between the announcement and the call, 0 when none is needed
+}
+
+type Proxies struct {
+	Proxies []ProxyDefinition `json:"proxies"`
+	Deposit uint128.Uint128   `json:"deposit"`
+}
+
+// GetProxies lists the proxies of real from Proxy.Proxies, an account without proxies has none
+func (c *Client) GetProxies(real []byte) (*Proxies, error) {
+	meta := c.metadata()
+	key, err := types.CreateStorageKey(meta, "Proxy", "Proxies", real)
+	if err != nil {
+		return nil, fmt.Errorf("can't create storage key %v", err)
+	}
+	raw, err := c.API.RPC.State.GetStorageRawLatest(key)
+	if err != nil {
+		return nil, fmt.Errorf("can't get storage for proxies %v", err)
+	}
+	res := &Proxies{}
+	if raw == nil || len(*raw) == 0 {
+		return res, nil
+	}
+	v, err := decodeStorageValue(meta, "Proxy", "Proxies", *raw)
+	if err != nil {
+		return nil, err
+	}
+	tuple, ok := v.([]interface{})
+	if !ok || len(tuple) != 2 {
+		return nil, fmt.Errorf("unexpected proxies %v", v)
+	}
+	defs, _ := tuple[0].([]interface{})
+	for _, d := range defs {
+		fields, ok := d.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected proxy definition %v", d)
+		}
+		def := ProxyDefinition{Delay: uint32(toBigInt(fields["delay"]).Uint64())}
+		if delegate := accountIdFromValue(fields["delegate"]); delegate != nil {
+			def.Delegate = types.HexEncodeToString(delegate)
+		}
+		if pt, ok := fields["proxy_type"].(*Variant); ok {
+			def.ProxyType = pt.Name
+		}
+		res.Proxies = append(res.Proxies, def)
+	}
+	res.Deposit = uint128.FromBig(toBigInt(tuple[1]))
+	return res, nil
+}
+
+/*
+Proxy.proxy dispatching ca as real, the signer must be a proxy of real. forceType restricts the
+proxy definition that is used, empty for any the signer has.
+*/
+func (c *Client) NewProxy(real []byte, forceType string, ca types.Call) (types.Call, error) {
+	return c.newCheckedCall("Proxy.proxy", real, proxyTypeOption(forceType), ca)
+}
+
+// Proxy.proxy_announced dispatching ca as real once the announcement of delegate is older than its delay
+func (c *Client) NewProxyAnnounced(delegate, real []byte, forceType string, ca types.Call) (types.Call, error) {
+	return c.newCheckedCall("Proxy.proxy_announced", delegate, real, proxyTypeOption(forceType), ca)
+}
+
+// Proxy.add_proxy registering delegate as a proxy of the signer
+func (c *Client) NewAddProxy(delegate []byte, proxyType string, delay uint32) (types.Call, error) {
+	return c.newCheckedCall("Proxy.add_proxy", delegate, proxyType, delay)
+}
+
+// Proxy.remove_proxy unregistering a proxy of the signer, the arguments must match the definition
+func (c *Client) NewRemoveProxy(delegate []byte, proxyType string, delay uint32) (types.Call, error) {
+	return c.newCheckedCall("Proxy.remove_proxy", delegate, proxyType, delay)
+}
+
+func proxyTypeOption(proxyType string) interface{} {
+	if proxyType == "" {
+		return nil
+	}
+	return proxyType
+}
+
+// SignProxyTransfer signs a transfer from real, dispatched through Proxy.proxy by from
+func (c *Client) SignProxyTransfer(from signer.Signer, real []byte, t Transfer, tip uint128.Uint128) (ext types.Extrinsic, err error) {
+	err = c.checkRuntimeVersion()
+	if err != nil {
+		return ext, err
+	}
+	ca, err := c.NewTransferCall(t)
+	if err != nil {
+		return ext, err
+	}
+	ca, err = c.NewProxy(real, "", ca)
+	if err != nil {
+		return ext, err
+	}
+	return c.SignCall(from, ca, tip)
+}
+
+// AuthorProxyTransfer submits a transfer of the funds of real, signed by its proxy from. CheckBalances checks it with CheckProxyTransfer
+func (c *Client) AuthorProxyTransfer(from signer.Signer, real []byte, t Transfer, tip uint128.Uint128) (txHash types.Hash, err error) {
+	ext, err := c.SignProxyTransfer(from, real, t, tip)
+	if err != nil {
+		return txHash, err
+	}
+	return c.submitTransfer(ext, real, t)
+}
@@ -0,0 +1,567 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/DataHighway-DHX/substrate-go/signer"
+	"github.com/DataHighway-DHX/substrate-go/uint128"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+var ErrNonceConsumed = errors.New("nonce was used by another transaction")
+
+// TrackedTx statuses
+const (
+	TrackPending       = "pending"
+	TrackIncluded      = "included"
+	TrackNonceConsumed = "nonce_consumed"
+	TrackAbandoned     = "abandoned"
+)
+
+/*
+TrackedTx is a submission followed by a Tracker. Every resubmission signs the same nonce, so at
+most one of the versions in Hashes can ever be included.
+*/
+type TrackedTx struct {
+	Id          string   `json:"id"`     // hash of the first submission
+	Signer      string   `json:"signer"` // hex account id
+	Nonce       uint64   `json:"nonce"`
+	Call        string   `json:"call"`         // hex encoded call, signed again on resubmission
+	Tip         string   `json:"tip"`          // planck, of the latest version
+	Extrinsic   string   `json:"extrinsic"`    // hex encoded latest version
+	Hashes      []string `json:"hashes"`       // every submitted version
+	SubmittedAt int64    `json:"submitted_at"` // best block height when the latest version was submitted
+	ValidUntil  int64    `json:"valid_until"`  // first block the latest version is no longer valid in, 0 when immortal
+	CheckedTo   int64    `json:"checked_to"`   // blocks up to this height were searched for the transaction
+	Attempts    int      `json:"attempts"`
+	Status      string   `json:"status"`
+	TxHash      string   `json:"tx_hash,omitempty"` // the included version
+	BlockHash   string   `json:"block_hash,omitempty"`
+	Height      int64    `json:"height,omitempty"`
+	Success     bool     `json:"success"`
+	Error       string   `json:"error,omitempty"` // dispatch error, or the last resubmission error while pending
+}
+
+// TxStore persists tracked transactions so that a restarted Tracker picks them up again
+type TxStore interface {
+	Load() ([]*TrackedTx, error)
+	Save(tx *TrackedTx) error
+	Delete(id string) error
+}
+
+// FileTxStore keeps tracked transactions in one JSON file, replaced atomically on every change
+type FileTxStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileTxStore(path string) *FileTxStore {
+	return &FileTxStore{path: path}
+}
+
+func (s *FileTxStore) read() (map[string]*TrackedTx, error) {
+	txs := make(map[string]*TrackedTx)
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return txs, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't read tx store %v", err)
+	}
+	err = json.Unmarshal(data, &txs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tx store %v", err)
+	}
+	return txs, nil
+}
+
+func (s *FileTxStore) write(txs map[string]*TrackedTx) error {
+	data, err := json.MarshalIndent(txs, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	err = os.WriteFile(tmp, data, 0600)
+	if err != nil {
+		return fmt.Errorf("can't write tx store %v", err)
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func (s *FileTxStore) Load() ([]*TrackedTx, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	txs, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*TrackedTx, 0, len(txs))
+	for _, tx := range txs {
+		res = append(res, tx)
+	}
+	return res, nil
+}
+
+func (s *FileTxStore) Save(tx *TrackedTx) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	txs, err := s.read()
+	if err != nil {
+		return err
+	}
+	txs[tx.Id] = tx
+	return s.write(txs)
+}
+
+func (s *FileTxStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	txs, err := s.read()
+	if err != nil {
+		return err
+	}
+	delete(txs, id)
+	return s.write(txs)
+}
+
+type RetryPolicy struct {
+	// resubmit a transaction that is not included within this many blocks, only on drop or expiry when zero
+	AfterBlocks int64
+	// stop resubmitting after this many submissions, unlimited when zero
+	MaxAttempts int
+	// added to the tip when the transaction is signed again, the pool only replaces a
+	// transaction with one of higher priority. When zero a valid transaction is re-broadcast as is
+	TipBump uint128.Uint128
+}
+
+/*
+Tracker persists submitted transactions and follows them until they are included in a finalized
+block. A transaction that is dropped from the pool, outlives its mortal era or is not included
+within RetryPolicy.AfterBlocks is submitted again with the same nonce, unless the best block
+already used the nonce. Once a finalized block used the nonce of the signer for anything else the
+transaction is never resubmitted and ends as nonce_consumed.
+A TrackedTx handed out by the Tracker is never changed afterwards, a new one replaces it on every
+update, use Get for the current state.
+*/
+type Tracker struct {
+	c        *Client
+	store    TxStore
+	policy   RetryPolicy
+	mu       sync.Mutex
+	checking sync.Mutex // one Check pass at a time
+	signers  map[string]signer.Signer
+	txs      map[string]*TrackedTx
+}
+
+// NewTracker loads the transactions of store, signers are needed to sign them again
+func (c *Client) NewTracker(store TxStore, policy RetryPolicy, signers ...signer.Signer) (*Tracker, error) {
+	txs, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	t := &Tracker{
+		c:       c,
+		store:   store,
+		policy:  policy,
+		signers: make(map[string]signer.Signer),
+		txs:     make(map[string]*TrackedTx),
+	}
+	for _, tx := range txs {
+		t.txs[tx.Id] = tx
+	}
+	for _, s := range signers {
+		t.AddSigner(s)
+	}
+	return t, nil
+}
+
+func (t *Tracker) AddSigner(s signer.Signer) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.signers[types.HexEncodeToString(signer.AccountId(s))] = s
+}
+
+func (t *Tracker) signer(accountId string) (signer.Signer, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.signers[accountId]
+	return s, ok
+}
+
+// Submit signs and submits a call and tracks it until it is included
+func (t *Tracker) Submit(from signer.Signer, ca types.Call, tip uint128.Uint128) (*TrackedTx, error) {
+	t.AddSigner(from)
+	head, err := t.c.API.RPC.Chain.GetHeaderLatest()
+	if err != nil {
+		return nil, fmt.Errorf("can't get latest header %v", err)
+	}
+	_, finalized, err := t.c.finalizedHead()
+	if err != nil {
+		return nil, err
+	}
+	accountId := signer.AccountId(from)
+	so, err := t.c.GetSignatureOptions(accountId, tip)
+	if err != nil {
+		return nil, fmt.Errorf("can't get signature options %v", err)
+	}
+	callHex, err := types.EncodeToHex(ca)
+	if err != nil {
+		return nil, fmt.Errorf("can't encode call %v", err)
+	}
+	tx := &TrackedTx{
+		Signer:    types.HexEncodeToString(accountId),
+		Nonce:     uint64(so.Nonce.Int64()),
+		Call:      callHex,
+		Status:    TrackPending,
+		CheckedTo: finalized,
+	}
+	ext, validUntil, err := t.sign(tx, from, so)
+	if err != nil {
+		t.c.releaseNonce(accountId, so)
+		return nil, err
+	}
+	err = t.submit(tx, ext, validUntil, int64(head.Number))
+	if err != nil {
+		t.c.submitFailed(ext, err)
+		return nil, err
+	}
+	tx.Id = tx.Hashes[0]
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.txs[tx.Id] = tx
+	return tx, t.store.Save(tx)
+}
+
+// sign signs the call of tx, validUntil is the first block the signed version is no longer valid in
+func (t *Tracker) sign(tx *TrackedTx, from signer.Signer, so types.SignatureOptions) (ext types.Extrinsic, validUntil int64, err error) {
+	ca, err := decodeCallHex(tx.Call)
+	if err != nil {
+		return ext, 0, err
+	}
+	ext, err = signExtrinsic(ca, so, from)
+	if err != nil {
+		return ext, 0, fmt.Errorf("can't sign extrinsic %v", err)
+	}
+	if so.Era.IsMortalEra {
+		checkpoint, err := t.c.API.RPC.Chain.GetHeader(so.BlockHash)
+		if err != nil {
+			return ext, 0, fmt.Errorf("can't get era checkpoint header %v", err)
+		}
+		period, _ := mortalEraPeriod(so.Era.AsMortalEra)
+		validUntil = int64(checkpoint.Number) + int64(period)
+	}
+	return ext, validUntil, nil
+}
+
+func (t *Tracker) submit(tx *TrackedTx, ext types.Extrinsic, validUntil, height int64) error {
+	hash, err := getTxId(ext)
+	if err != nil {
+		return err
+	}
+	extHex, err := types.EncodeToHex(ext)
+	if err != nil {
+		return fmt.Errorf("can't encode extrinsic %v", err)
+	}
+	_, err = t.c.API.RPC.Author.SubmitExtrinsic(ext)
+	if err != nil {
+		return fmt.Errorf("can't SubmitExtrinsic %v", err)
+	}
+	tip := big.Int(ext.Signature.Tip)
+	tx.Tip = tip.String()
+	tx.Extrinsic = extHex
+	tx.ValidUntil = validUntil
+	tx.Hashes = append(tx.Hashes, hash)
+	tx.SubmittedAt = height
+	tx.Attempts++
+	return nil
+}
+
+// Get returns a tracked transaction by the hash of its first submission
+func (t *Tracker) Get(id string) (*TrackedTx, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tx, ok := t.txs[id]
+	return tx, ok
+}
+
+func (t *Tracker) Pending() []*TrackedTx {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var res []*TrackedTx
+	for _, tx := range t.txs {
+		if tx.Status == TrackPending {
+			res = append(res, tx)
+		}
+	}
+	return res
+}
+
+// Forget stops tracking a transaction and removes it from the store
+func (t *Tracker) Forget(id string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.txs, id)
+	return t.store.Delete(id)
+}
+
+// pendingCopies returns copies of the pending transactions for a Check pass to work on without the lock
+func (t *Tracker) pendingCopies() []*TrackedTx {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var res []*TrackedTx
+	for _, tx := range t.txs {
+		if tx.Status == TrackPending {
+			c := *tx
+			c.Hashes = append([]string(nil), tx.Hashes...)
+			res = append(res, &c)
+		}
+	}
+	return res
+}
+
+// update replaces and saves the transactions of a Check pass, except the ones forgotten meanwhile
+func (t *Tracker) update(txs []*TrackedTx) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, tx := range txs {
+		if _, ok := t.txs[tx.Id]; !ok {
+			continue
+		}
+		t.txs[tx.Id] = tx
+		if err := t.store.Save(tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+Check searches the finalized blocks for the pending transactions and resubmits the ones the policy
+asks for. It returns the transactions that reached a final status in this pass. Resubmission
+errors are kept in TrackedTx.Error and retried on the next pass. The node is queried without
+holding the lock of the Tracker.
+*/
+func (t *Tracker) Check() (done []*TrackedTx, err error) {
+	t.checking.Lock()
+	defer t.checking.Unlock()
+
+	pending := t.pendingCopies()
+	if len(pending) == 0 {
+		return nil, nil
+	}
+	// whatever this pass got to, e.g. a resubmitted version, is kept when it fails half way
+	defer func() {
+		if uerr := t.update(pending); err == nil {
+			err = uerr
+		}
+	}()
+
+	from := int64(-1)
+	for _, tx := range pending {
+		if from < 0 || tx.CheckedTo+1 < from {
+			from = tx.CheckedTo + 1
+		}
+	}
+	header, err := t.c.API.RPC.Chain.GetHeaderLatest()
+	if err != nil {
+		return nil, fmt.Errorf("can't get latest header %v", err)
+	}
+	head := int64(header.Number)
+	headHash, err := t.c.API.RPC.Chain.GetBlockHash(uint64(head))
+	if err != nil {
+		return nil, fmt.Errorf("can't get block hash %v", err)
+	}
+	finalizedHash, finalized, err := t.c.finalizedHead()
+	if err != nil {
+		return nil, err
+	}
+
+	for height := from; height <= finalized; height++ {
+		blockHash, err := t.c.API.RPC.Chain.GetBlockHash(uint64(height))
+		if err != nil {
+			return done, fmt.Errorf("can't get block hash %v", err)
+		}
+		block, err := t.c.API.RPC.Chain.GetBlock(blockHash)
+		if err != nil {
+			return done, fmt.Errorf("get block error: %v", err)
+		}
+		for i, ext := range block.Block.Extrinsics {
+			id, err := getTxId(ext)
+			if err != nil {
+				return done, err
+			}
+			for _, tx := range pending {
+				if tx.Status != TrackPending || tx.CheckedTo >= height || !containsString(tx.Hashes, id) {
+					continue
+				}
+				err = t.included(tx, id, blockHash, height, i)
+				if err != nil {
+					return done, err
+				}
+				done = append(done, tx)
+			}
+		}
+		for _, tx := range pending {
+			if tx.Status == TrackPending && tx.CheckedTo < height {
+				tx.CheckedTo = height
+			}
+		}
+	}
+
+	var pool map[string]bool
+	for _, tx := range pending {
+		if tx.Status != TrackPending {
+			continue
+		}
+		if pool == nil {
+			pool, err = t.poolHashes()
+			if err != nil {
+				return done, err
+			}
+		}
+		accountId, err := types.HexDecodeString(tx.Signer)
+		if err != nil {
+			return done, fmt.Errorf("invalid signer %v", err)
+		}
+		final, err := t.c.accountAt(accountId, &finalizedHash)
+		if err != nil {
+			return done, err
+		}
+		if uint64(final.Nonce) > tx.Nonce {
+			// searched up to the finalized head without finding any version, so another transaction used the nonce
+			tx.Status, tx.Error = TrackNonceConsumed, ErrNonceConsumed.Error()
+			done = append(done, tx)
+			continue
+		}
+		best, err := t.c.accountAt(accountId, &headHash)
+		if err != nil {
+			return done, err
+		}
+		latest := tx.Hashes[len(tx.Hashes)-1]
+		expired := tx.ValidUntil > 0 && head+1 >= tx.ValidUntil
+		switch {
+		case uint64(best.Nonce) > tx.Nonce:
+			// the nonce is used in a block that is not finalized yet, decided once it is or after a reorg
+		case pool[latest] && !expired && (t.policy.AfterBlocks == 0 || head-tx.SubmittedAt < t.policy.AfterBlocks):
+			// still waiting in the pool
+		case t.policy.MaxAttempts > 0 && tx.Attempts >= t.policy.MaxAttempts:
+			tx.Status, tx.Error = TrackAbandoned, fmt.Sprintf("not included after %d submissions", tx.Attempts)
+			done = append(done, tx)
+		default:
+			err = t.resubmit(tx, head, expired)
+			tx.Error = ""
+			if err != nil {
+				tx.Error = err.Error()
+			}
+		}
+	}
+	return done, nil
+}
+
+// resubmit re-broadcasts the latest version of tx, or signs it again with a bumped tip
+func (t *Tracker) resubmit(tx *TrackedTx, head int64, expired bool) error {
+	if !expired && t.policy.TipBump.IsZero() {
+		var ext types.Extrinsic
+		err := types.DecodeFromHex(tx.Extrinsic, &ext)
+		if err != nil {
+			return fmt.Errorf("invalid tracked extrinsic %v", err)
+		}
+		return t.submit(tx, ext, tx.ValidUntil, head)
+	}
+	from, ok := t.signer(tx.Signer)
+	if !ok {
+		return fmt.Errorf("no signer for %s to sign the transaction again", tx.Signer)
+	}
+	tip, err := uint128.FromString(tx.Tip)
+	if err != nil {
+		return err
+	}
+	so, err := t.c.signatureOptions(tip.Add(t.policy.TipBump))
+	if err != nil {
+		return fmt.Errorf("can't get signature options %v", err)
+	}
+	so.Nonce = types.NewUCompactFromUInt(tx.Nonce)
+	ext, validUntil, err := t.sign(tx, from, so)
+	if err != nil {
+		return err
+	}
+	return t.submit(tx, ext, validUntil, head)
+}
+
+func (t *Tracker) included(tx *TrackedTx, id string, blockHash types.Hash, height int64, index int) error {
+	events, err := t.c.GetBlockEvents(blockHash)
+	if err != nil {
+		return err
+	}
+	meta, err := t.c.metadataAt(blockHash)
+	if err != nil {
+		return err
+	}
+	failure, failed := extrinsicFailure(meta, extrinsicEvents(events, index))
+	tx.Status = TrackIncluded
+	tx.TxHash = id
+	tx.BlockHash = blockHash.Hex()
+	tx.Height = height
+	tx.CheckedTo = height
+	tx.Success = !failed
+	tx.Error = failure
+	return nil
+}
+
+func (t *Tracker) poolHashes() (map[string]bool, error) {
+	exts, err := t.c.API.RPC.Author.PendingExtrinsics()
+	if err != nil {
+		return nil, fmt.Errorf("can't get pending extrinsics %v", err)
+	}
+	pool := make(map[string]bool, len(exts))
+	for _, ext := range exts {
+		id, err := getTxId(ext)
+		if err != nil {
+			return nil, err
+		}
+		pool[id] = true
+	}
+	return pool, nil
+}
+
+/*
+Run checks the tracked transactions every interval until ctx is done. Transactions that reached a
+final status go to done, errors of a pass go to onError and the pass is retried on the next tick.
+*/
+func (t *Tracker) Run(ctx context.Context, interval time.Duration, done func(*TrackedTx), onError func(error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			finished, err := t.Check()
+			if err != nil && onError != nil {
+				onError(err)
+			}
+			for _, tx := range finished {
+				if done != nil {
+					done(tx)
+				}
+			}
+		}
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
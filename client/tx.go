@@ -3,110 +3,320 @@ package client
 import (
 	"errors"
 	"fmt"
+	"math/bits"
 
-	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/DataHighway-DHX/substrate-go/signer"
+	"github.com/DataHighway-DHX/substrate-go/uint128"
 	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
 	"github.com/decred/base58"
 )
 
-func (c *Client) AuthorTransferAsset(senderSecret, recieverAccId string, value, tip uint64) (txHash types.Hash, err error) {
-	from, err := signature.KeyringPairFromSecret(
-		senderSecret,
-		c.NetId)
+// TransferKind selects the Balances call used to move funds
+type TransferKind int
+
+const (
+	// Balances.transfer_allow_death, or Balances.transfer on runtimes that predate it
+	TransferAllowDeath TransferKind = iota
+	// Balances.transfer_keep_alive, fails rather than reaping the sender
+	TransferKeepAlive
+	// Balances.transfer_all, sweeps the whole transferable balance
+	TransferAll
+	// Balances.force_transfer, requires root origin
+	ForceTransfer
+)
+
+var ErrCallNotFound = errors.New("call not found in runtime metadata")
+
+type Transfer struct {
+	Kind   TransferKind
+	Source string // SS58 address or hex account id, ForceTransfer only
+	Dest   string // SS58 address or hex account id
+	// used instead of Dest when set, e.g. for Index or Address20 destinations
+	DestAddress *types.MultiAddress
+	Value       uint128.Uint128 // planck, ignored by TransferAll
+	KeepAlive   bool            // TransferAll only, keep the sender above the existential deposit
+	// accept SS58 addresses with another network prefix than Client.NetId
+	AllowOtherNetwork bool
+}
+
+func (c *Client) AuthorTransferAsset(senderSecret, recieverAccId string, value, tip uint128.Uint128) (txHash types.Hash, err error) {
+	from, err := signer.FromSecret(senderSecret, signer.Sr25519)
 	if err != nil {
 		return txHash, fmt.Errorf("can't get sender key pair %v", err)
 	}
+	return c.AuthorTransfer(from, Transfer{Kind: TransferAllowDeath, Dest: recieverAccId, Value: value}, tip)
+}
+
+func (c *Client) AuthorTransfer(from signer.Signer, t Transfer, tip uint128.Uint128) (txHash types.Hash, err error) {
+	ext, err := c.SignTransfer(from, t, tip)
+	if err != nil {
+		return txHash, err
+	}
+	return c.submitTransfer(ext, nil, t)
+}
 
-	to, err := types.NewMultiAddressFromHexAccountID(recieverAccId)
+// AuthorTransferAndWatch submits a transfer and streams its transaction pool status
+func (c *Client) AuthorTransferAndWatch(from signer.Signer, t Transfer, tip uint128.Uint128) (*TxWatch, error) {
+	ext, err := c.SignTransfer(from, t, tip)
+	if err != nil {
+		return nil, err
+	}
+	err = c.vetTransfers(ext, nil, t)
 	if err != nil {
-		return txHash, fmt.Errorf("can't get reciever multi address %v", err)
+		return nil, err
 	}
+	return c.SubmitAndWatch(ext)
+}
 
-	amount := types.NewUCompactFromUInt(value)
-	c.Meta, err = c.API.RPC.State.GetMetadataLatest()
+// vetTransfers runs the balance checks and the dry run of signed transfers from origin, the signer when nil
+func (c *Client) vetTransfers(ext types.Extrinsic, origin []byte, transfers ...Transfer) error {
+	err := c.checkBalances(ext, origin, transfers...)
 	if err != nil {
-		return txHash, fmt.Errorf("can't get latest metadata %v", err)
+		return err
 	}
+	return c.preflight(ext)
+}
 
-	so, err := c.GetSignatureOptions(from, tip)
+// submitTransfer vets a signed transfer from origin, the signer when nil, and submits it
+func (c *Client) submitTransfer(ext types.Extrinsic, origin []byte, t Transfer) (txHash types.Hash, err error) {
+	err = c.vetTransfers(ext, origin, t)
 	if err != nil {
-		return txHash, fmt.Errorf("can't get signature options %v", err)
+		return txHash, err
 	}
+	txHash, err = c.API.RPC.Author.SubmitExtrinsic(ext)
+	if err != nil {
+		c.submitFailed(ext, err)
+		return txHash, fmt.Errorf("can't SubmitExtrinsic %v", err)
+	}
+	return
+}
 
-	ca, err := NewCall(c.Meta, "Balances.transfer", to, amount)
+// SignTransfer builds and signs a transfer without submitting it
+func (c *Client) SignTransfer(from signer.Signer, t Transfer, tip uint128.Uint128) (ext types.Extrinsic, err error) {
+	err = c.checkRuntimeVersion()
 	if err != nil {
-		return txHash, fmt.Errorf("can't get Balances.transfer call from metadata %v", err)
+		return ext, err
 	}
+	ca, err := c.NewTransferCall(t)
+	if err != nil {
+		return ext, err
+	}
+	return c.SignCall(from, ca, tip)
+}
 
-	ext := types.NewExtrinsic(ca)
+// SignCall signs any call without submitting it
+func (c *Client) SignCall(from signer.Signer, ca types.Call, tip uint128.Uint128) (ext types.Extrinsic, err error) {
+	accountId := signer.AccountId(from)
+	so, err := c.GetSignatureOptions(accountId, tip)
+	if err != nil {
+		return ext, fmt.Errorf("can't get signature options %v", err)
+	}
 
-	err = ext.Sign(from, so)
+	ext, err = signExtrinsic(ca, so, from)
 	if err != nil {
-		return txHash, fmt.Errorf("can't sign extrinsic %v", err)
+		c.releaseNonce(accountId, so)
+		return ext, fmt.Errorf("can't sign extrinsic %v", err)
 	}
+	return ext, nil
+}
 
-	txHash, err = c.API.RPC.Author.SubmitExtrinsic(ext)
+func signExtrinsic(ca types.Call, so types.SignatureOptions, from signer.Signer) (types.Extrinsic, error) {
+	p, err := newPayload(ca, so)
 	if err != nil {
-		return txHash, fmt.Errorf("can't SubmitExtrinsic %v", err)
+		return types.Extrinsic{}, err
+	}
+	payload, err := signingPayload(p)
+	if err != nil {
+		return types.Extrinsic{}, err
+	}
+	sig, err := from.Sign(payload)
+	if err != nil {
+		return types.Extrinsic{}, err
+	}
+	return assembleExtrinsic(ca, p, signer.AccountId(from), from.CryptoType(), sig)
+}
+
+// NewTransferCall builds the Balances call for t that the connected runtime supports
+func (c *Client) NewTransferCall(t Transfer) (ca types.Call, err error) {
+	var to types.MultiAddress
+	if t.DestAddress != nil {
+		to = *t.DestAddress
+	} else {
+		to, err = c.ParseAddress(t.Dest, t.AllowOtherNetwork)
+		if err != nil {
+			return types.Call{}, fmt.Errorf("can't get reciever multi address %w", err)
+		}
+	}
+	amount := types.NewUCompact(t.Value.Big())
+
+	var name string
+	var args []interface{}
+	switch t.Kind {
+	case TransferAllowDeath:
+		name = "Balances.transfer_allow_death"
+		if !hasCall(c.metadata(), name) {
+			name = "Balances.transfer"
+		}
+		args = []interface{}{to, amount}
+	case TransferKeepAlive:
+		name = "Balances.transfer_keep_alive"
+		args = []interface{}{to, amount}
+	case TransferAll:
+		name = "Balances.transfer_all"
+		args = []interface{}{to, t.KeepAlive}
+	case ForceTransfer:
+		source, err := c.ParseAddress(t.Source, t.AllowOtherNetwork)
+		if err != nil {
+			return types.Call{}, fmt.Errorf("can't get source multi address %w", err)
+		}
+		name = "Balances.force_transfer"
+		args = []interface{}{source, to, amount}
+	default:
+		return types.Call{}, fmt.Errorf("unknown transfer kind %d", t.Kind)
+	}
+
+	return c.newCheckedCall(name, args...)
+}
+
+// newCheckedCall encodes a call of the latest runtime, ErrCallNotFound when the runtime lacks it
+func (c *Client) newCheckedCall(name string, args ...interface{}) (types.Call, error) {
+	meta, rv := c.runtime()
+	if !hasCall(meta, name) {
+		return types.Call{}, fmt.Errorf("%w: %s (spec %s v%d)", ErrCallNotFound, name,
+			rv.SpecName, rv.SpecVersion)
+	}
+	ca, err := NewCall(meta, name, args...)
+	if err != nil {
+		return types.Call{}, fmt.Errorf("can't get %s call from metadata %v", name, err)
+	}
+	return ca, nil
+}
+
+func hasCall(m *types.Metadata, call string) bool {
+	_, err := m.FindCallIndex(call)
+	return err == nil
+}
+
+// GetSignatureOptions returns the era, nonce and hashes to sign a transaction of accountId
+func (c *Client) GetSignatureOptions(accountId []byte, tip uint128.Uint128) (so types.SignatureOptions, err error) {
+	so, err = c.signatureOptions(tip)
+	if err != nil {
+		return so, err
+	}
+	nonce, err := c.nextNonce(accountId)
+	if err != nil {
+		return so, err
 	}
+	so.Nonce = types.NewUCompactFromUInt(nonce)
 	return
 }
 
-func (c *Client) GetSignatureOptions(signer signature.KeyringPair, tip uint64) (so types.SignatureOptions, err error) {
+// signatureOptions returns the signature options without the nonce
+func (c *Client) signatureOptions(tip uint128.Uint128) (so types.SignatureOptions, err error) {
 	gHash, err := c.GetGenesisHash()
 	if err != nil {
 		return so, err
 	}
-	ai, err := c.GetAccountInfo(signer)
+	err = c.checkRuntimeVersion()
 	if err != nil {
 		return so, err
 	}
-	err = c.checkRuntimeVersion()
+	era, blockHash, err := c.getEra(*gHash)
 	if err != nil {
 		return so, err
 	}
-	rv := c.RuntimeVersion
+	_, rv := c.runtime()
 	so = types.SignatureOptions{
-		BlockHash:          *gHash,
-		Era:                types.ExtrinsicEra{IsMortalEra: false, IsImmortalEra: true},
+		BlockHash:          blockHash,
+		Era:                era,
 		GenesisHash:        *gHash,
-		Nonce:              types.NewUCompactFromUInt(uint64(ai.Nonce)),
 		SpecVersion:        rv.SpecVersion,
-		Tip:                types.NewUCompactFromUInt(tip),
+		Tip:                types.NewUCompact(tip.Big()),
 		TransactionVersion: rv.TransactionVersion,
 	}
 	return
 }
 
-func NewCall(m *types.Metadata, call string, args ...interface{}) (types.Call, error) {
-	c, err := m.FindCallIndex(call)
+const DefaultEraPeriod = 64
+
+/*
+Era and checkpoint block hash to sign with. Transactions are mortal by default, valid for
+EraPeriod blocks from the latest finalized block. ImmortalEra signs against the genesis hash instead.
+*/
+func (c *Client) getEra(genesisHash types.Hash) (types.ExtrinsicEra, types.Hash, error) {
+	if c.ImmortalEra {
+		return types.ExtrinsicEra{IsImmortalEra: true}, genesisHash, nil
+	}
+	period := c.EraPeriod
+	if period == 0 {
+		period = DefaultEraPeriod
+	}
+	finalized, err := c.API.RPC.Chain.GetFinalizedHead()
 	if err != nil {
-		return types.Call{}, err
+		return types.ExtrinsicEra{}, types.Hash{}, fmt.Errorf("can't get finalized head %v", err)
 	}
-	c = types.CallIndex{
-		SectionIndex: c.MethodIndex,
-		MethodIndex:  c.SectionIndex,
+	header, err := c.API.RPC.Chain.GetHeader(finalized)
+	if err != nil {
+		return types.ExtrinsicEra{}, types.Hash{}, fmt.Errorf("can't get finalized header %v", err)
 	}
-
-	var a []byte
-	ci, err := types.Encode(c.SectionIndex)
+	era, birth, err := NewMortalEra(period, uint64(header.Number))
 	if err != nil {
-		return types.Call{}, err
+		return types.ExtrinsicEra{}, types.Hash{}, err
 	}
-	a = append(a, ci...)
-
-	for _, arg := range args {
-		e, err := types.Encode(arg)
+	blockHash := finalized
+	if birth != uint64(header.Number) {
+		blockHash, err = c.API.RPC.Chain.GetBlockHash(birth)
 		if err != nil {
-			return types.Call{}, err
+			return types.ExtrinsicEra{}, types.Hash{}, fmt.Errorf("can't get era checkpoint block hash %v", err)
 		}
-		a = append(a, e...)
 	}
+	return era, blockHash, nil
+}
+
+/*
+Mortal era starting at block current, valid for period blocks. period must be a power of two
+from 4 to 65536. Returns the era and the number of its checkpoint block, whose hash is signed.
+*/
+func NewMortalEra(period, current uint64) (types.ExtrinsicEra, uint64, error) {
+	if period < 4 || period > 1<<16 || period&(period-1) != 0 {
+		return types.ExtrinsicEra{}, 0, fmt.Errorf("invalid era period %d, must be a power of two from 4 to 65536", period)
+	}
+	quantizeFactor := period >> 12
+	if quantizeFactor < 1 {
+		quantizeFactor = 1
+	}
+	phase := current % period / quantizeFactor * quantizeFactor
+
+	low := uint64(bits.TrailingZeros64(period)) - 1
+	if low < 1 {
+		low = 1
+	}
+	if low > 15 {
+		low = 15
+	}
+	encoded := uint16(low) | uint16(phase/quantizeFactor)<<4
 
-	return types.Call{
-		CallIndex: c,
-		Args:      a,
-	}, nil
+	birth := current
+	if birth < phase {
+		birth = phase
+	}
+	birth = (birth-phase)/period*period + phase
+
+	return types.ExtrinsicEra{
+		IsMortalEra: true,
+		AsMortalEra: types.MortalEra{First: byte(encoded), Second: byte(encoded >> 8)},
+	}, birth, nil
+}
+
+// mortalEraPeriod returns the period and phase of an encoded mortal era
+func mortalEraPeriod(era types.MortalEra) (period, phase uint64) {
+	encoded := uint64(era.First) | uint64(era.Second)<<8
+	period = 2 << (encoded & 0xf)
+	quantizeFactor := period >> 12
+	if quantizeFactor < 1 {
+		quantizeFactor = 1
+	}
+	return period, (encoded >> 4) * quantizeFactor
 }
 
 func DecodeToPub(address string) ([]byte, error) {
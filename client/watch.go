@@ -0,0 +1,273 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/config"
+	gethrpc "github.com/centrifuge/go-substrate-rpc-client/v4/gethrpc"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+var (
+	ErrTxInvalid         = errors.New("transaction is invalid")
+	ErrTxDropped         = errors.New("transaction was dropped from the pool")
+	ErrTxUsurped         = errors.New("transaction was usurped by another with the same nonce")
+	ErrTxFinalityTimeout = errors.New("block with the transaction was not finalized in time")
+)
+
+// TxStatus is a transaction pool status reported by author_submitAndWatchExtrinsic
+type TxStatus struct {
+	// Future, Ready, Broadcast, InBlock, Retracted, FinalityTimeout, Finalized, Usurped, Dropped or Invalid
+	Status    string     `json:"status"`
+	BlockHash types.Hash `json:"block_hash"` // InBlock, Retracted, FinalityTimeout and Finalized
+	Usurper   types.Hash `json:"usurper"`    // Usurped
+	Peers     []string   `json:"peers"`      // Broadcast
+}
+
+func newTxStatus(s types.ExtrinsicStatus) TxStatus {
+	switch {
+	case s.IsFuture:
+		return TxStatus{Status: "Future"}
+	case s.IsReady:
+		return TxStatus{Status: "Ready"}
+	case s.IsBroadcast:
+		peers := make([]string, len(s.AsBroadcast))
+		for i, p := range s.AsBroadcast {
+			peers[i] = string(p)
+		}
+		return TxStatus{Status: "Broadcast", Peers: peers}
+	case s.IsInBlock:
+		return TxStatus{Status: "InBlock", BlockHash: s.AsInBlock}
+	case s.IsRetracted:
+		return TxStatus{Status: "Retracted", BlockHash: s.AsRetracted}
+	case s.IsFinalityTimeout:
+		return TxStatus{Status: "FinalityTimeout", BlockHash: s.AsFinalityTimeout}
+	case s.IsFinalized:
+		return TxStatus{Status: "Finalized", BlockHash: s.AsFinalized}
+	case s.IsUsurped:
+		return TxStatus{Status: "Usurped", Usurper: s.AsUsurped}
+	case s.IsDropped:
+		return TxStatus{Status: "Dropped"}
+	case s.IsInvalid:
+		return TxStatus{Status: "Invalid"}
+	}
+	return TxStatus{Status: "Unknown"}
+}
+
+// Final reports whether the pool sends no further status for the transaction
+func (s TxStatus) Final() bool {
+	switch s.Status {
+	case "Finalized", "FinalityTimeout", "Usurped", "Dropped", "Invalid":
+		return true
+	}
+	return false
+}
+
+// Err returns the error for statuses that end the transaction without finalizing it
+func (s TxStatus) Err() error {
+	switch s.Status {
+	case "Invalid":
+		return ErrTxInvalid
+	case "Dropped":
+		return ErrTxDropped
+	case "Usurped":
+		return fmt.Errorf("%w: %s", ErrTxUsurped, s.Usurper.Hex())
+	case "FinalityTimeout":
+		return fmt.Errorf("%w: %s", ErrTxFinalityTimeout, s.BlockHash.Hex())
+	}
+	return nil
+}
+
+// TxWatch streams the status of a submitted transaction until a final status or Unsubscribe
+type TxWatch struct {
+	TxHash  types.Hash
+	ext     types.Extrinsic
+	sub     *gethrpc.ClientSubscription
+	updates chan types.ExtrinsicStatus
+	status  chan TxStatus
+	err     chan error
+	done    chan struct{}
+	once    sync.Once
+}
+
+// SubmitAndWatch submits a signed extrinsic with author_submitAndWatchExtrinsic
+func (c *Client) SubmitAndWatch(ext types.Extrinsic) (*TxWatch, error) {
+	txid, err := getTxId(ext)
+	if err != nil {
+		return nil, err
+	}
+	txHash, err := types.NewHashFromHexString(txid)
+	if err != nil {
+		return nil, err
+	}
+	enc, err := types.EncodeToHex(ext)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), config.Default().SubscribeTimeout)
+	defer cancel()
+	// subscribed directly rather than with Author.SubmitAndWatchExtrinsic, its Unsubscribe closes the
+	// status channel while a status still buffered in the subscription can be sent on it
+	updates := make(chan types.ExtrinsicStatus)
+	sub, err := c.API.Client.Subscribe(ctx, "author", "submitAndWatchExtrinsic", "unwatchExtrinsic", "extrinsicUpdate", updates, enc)
+	if err != nil {
+		c.submitFailed(ext, err)
+		return nil, fmt.Errorf("can't SubmitAndWatchExtrinsic %v", err)
+	}
+	w := &TxWatch{
+		TxHash:  txHash,
+		ext:     ext,
+		sub:     sub,
+		updates: updates,
+		status:  make(chan TxStatus),
+		err:     make(chan error, 1),
+		done:    make(chan struct{}),
+	}
+	go w.run()
+	return w, nil
+}
+
+func (w *TxWatch) run() {
+	defer close(w.status)
+	defer w.sub.Unsubscribe()
+	for {
+		select {
+		case <-w.done:
+			return
+		case err, ok := <-w.sub.Err():
+			if ok && err != nil {
+				w.err <- err
+			}
+			return
+		case s := <-w.updates:
+			st := newTxStatus(s)
+			select {
+			case w.status <- st:
+			case <-w.done:
+				return
+			}
+			if st.Final() {
+				return
+			}
+		}
+	}
+}
+
+// Status returns the status stream, it is closed after a final status or a subscription error
+func (w *TxWatch) Status() <-chan TxStatus {
+	return w.status
+}
+
+// Err returns the subscription error, if any, once Status is closed
+func (w *TxWatch) Err() <-chan error {
+	return w.err
+}
+
+func (w *TxWatch) Unsubscribe() {
+	w.once.Do(func() { close(w.done) })
+}
+
+// WaitUntil selects the status Wait returns at
+type WaitUntil int
+
+const (
+	UntilInBlock WaitUntil = iota
+	UntilFinalized
+)
+
+// TxResult is the outcome of a transaction included in a block
+type TxResult struct {
+	TxHash         types.Hash      `json:"tx_hash"`
+	Status         TxStatus        `json:"status"`
+	BlockHash      types.Hash      `json:"block_hash"`
+	Height         int64           `json:"height"`
+	ExtrinsicIndex int             `json:"extrinsic_index"`
+	Success        bool            `json:"success"`
+	Error          string          `json:"error,omitempty"` // dispatch error when Success is false
+	Events         []*DecodedEvent `json:"events"`
+}
+
+// SubmitAndWait submits a signed extrinsic and waits until it is in a block or finalized
+func (c *Client) SubmitAndWait(ctx context.Context, ext types.Extrinsic, until WaitUntil) (*TxResult, error) {
+	w, err := c.SubmitAndWatch(ext)
+	if err != nil {
+		return nil, err
+	}
+	return c.Wait(ctx, w, until)
+}
+
+/*
+Wait follows a TxWatch until the transaction is in a block (UntilInBlock) or finalized (UntilFinalized)
+and returns its events. Invalid, Dropped, Usurped and FinalityTimeout end the wait with an error.
+A transaction that is included but fails to dispatch is returned with Success false.
+*/
+func (c *Client) Wait(ctx context.Context, w *TxWatch, until WaitUntil) (*TxResult, error) {
+	defer w.Unsubscribe()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case st, ok := <-w.Status():
+			if !ok {
+				select {
+				case err := <-w.Err():
+					return nil, fmt.Errorf("transaction status subscription error: %v", err)
+				default:
+					return nil, errors.New("transaction status subscription closed")
+				}
+			}
+			if err := st.Err(); err != nil {
+				if st.Status != "FinalityTimeout" {
+					c.submitFailed(w.ext, err)
+				}
+				return nil, err
+			}
+			if st.Status == "Finalized" || (st.Status == "InBlock" && until == UntilInBlock) {
+				return c.txResult(w.TxHash, st)
+			}
+		}
+	}
+}
+
+func (c *Client) txResult(txHash types.Hash, st TxStatus) (*TxResult, error) {
+	block, err := c.API.RPC.Chain.GetBlock(st.BlockHash)
+	if err != nil {
+		return nil, fmt.Errorf("get block error: %v", err)
+	}
+	res := &TxResult{
+		TxHash:         txHash,
+		Status:         st,
+		BlockHash:      st.BlockHash,
+		Height:         int64(block.Block.Header.Number),
+		ExtrinsicIndex: -1,
+	}
+	for i, ext := range block.Block.Extrinsics {
+		id, err := getTxId(ext)
+		if err != nil {
+			return nil, err
+		}
+		if id == txHash.Hex() {
+			res.ExtrinsicIndex = i
+			break
+		}
+	}
+	if res.ExtrinsicIndex < 0 {
+		return nil, fmt.Errorf("%w: %s in block %s", ErrExtrinsicNotFound, txHash.Hex(), st.BlockHash.Hex())
+	}
+	events, err := c.GetBlockEvents(st.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := c.metadataAt(st.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+	res.Events = extrinsicEvents(events, res.ExtrinsicIndex)
+	failure, failed := extrinsicFailure(meta, res.Events)
+	res.Success = !failed
+	res.Error = failure
+	return res, nil
+}
@@ -3,8 +3,13 @@ module github.com/DataHighway-DHX/substrate-go
 go 1.18
 
 require (
+	github.com/ChainSafe/go-schnorrkel v1.0.0
 	github.com/centrifuge/go-substrate-rpc-client/v4 v4.0.3
+	github.com/cosmos/go-bip39 v1.0.0
 	github.com/decred/base58 v1.0.3
+	github.com/ethereum/go-ethereum v1.10.17
+	github.com/gorilla/websocket v1.5.0
+	github.com/gtank/merlin v0.1.1
 	github.com/huandu/xstrings v1.3.2
 	github.com/shopspring/decimal v1.3.1
 	github.com/vedhavyas/go-subkey v1.0.3
@@ -12,14 +17,9 @@ require (
 )
 
 require (
-	github.com/ChainSafe/go-schnorrkel v1.0.0 // indirect
-	github.com/cosmos/go-bip39 v1.0.0 // indirect
 	github.com/deckarep/golang-set v1.8.0 // indirect
 	github.com/decred/dcrd/crypto/blake256 v1.0.0 // indirect
-	github.com/ethereum/go-ethereum v1.10.17 // indirect
 	github.com/go-stack/stack v1.8.1 // indirect
-	github.com/gorilla/websocket v1.5.0 // indirect
-	github.com/gtank/merlin v0.1.1 // indirect
 	github.com/gtank/ristretto255 v0.1.2 // indirect
 	github.com/mimoo/StrobeGo v0.0.0-20210601165009-122bf33a46e0 // indirect
 	github.com/pierrec/xxHash v0.1.5 // indirect
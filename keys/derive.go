@@ -0,0 +1,146 @@
+package keys
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ChainSafe/go-schnorrkel"
+	"github.com/DataHighway-DHX/substrate-go/signer"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/gtank/merlin"
+	"golang.org/x/crypto/blake2b"
+)
+
+var ErrHardJunction = errors.New("hard junctions can't be derived from a public key")
+
+// Junction is one step of a derivation path, "//name" is hard and "/name" soft
+type Junction struct {
+	ChainCode [32]byte
+	Hard      bool
+}
+
+/*
+ParseJunction parses one junction without its leading slashes. Numbers are encoded as u64 little
+endian, anything else as a SCALE string, and codes longer than 32 bytes are blake2_256 hashed.
+*/
+func ParseJunction(code string, hard bool) (Junction, error) {
+	j := Junction{Hard: hard}
+	if code == "" || strings.Contains(code, "/") {
+		return j, fmt.Errorf("invalid junction %q", code)
+	}
+	var b []byte
+	if n, err := strconv.ParseUint(code, 10, 64); err == nil {
+		b = make([]byte, 8)
+		binary.LittleEndian.PutUint64(b, n)
+	} else {
+		b, err = types.Encode(code)
+		if err != nil {
+			return j, err
+		}
+	}
+	if len(b) > 32 {
+		h := blake2b.Sum256(b)
+		b = h[:]
+	}
+	copy(j.ChainCode[:], b)
+	return j, nil
+}
+
+// ParsePath parses a derivation path such as "//hot//0" or "/deposit/42"
+func ParsePath(path string) ([]Junction, error) {
+	var junctions []Junction
+	rest := path
+	for rest != "" {
+		if !strings.HasPrefix(rest, "/") {
+			return nil, fmt.Errorf("invalid derivation path %q", path)
+		}
+		rest = rest[1:]
+		hard := strings.HasPrefix(rest, "/")
+		if hard {
+			rest = rest[1:]
+		}
+		code := rest
+		if i := strings.Index(rest, "/"); i >= 0 {
+			code, rest = rest[:i], rest[i:]
+		} else {
+			rest = ""
+		}
+		j, err := ParseJunction(code, hard)
+		if err != nil {
+			return nil, fmt.Errorf("invalid derivation path %q: %v", path, err)
+		}
+		junctions = append(junctions, j)
+	}
+	return junctions, nil
+}
+
+// ParseURI splits a secret URI "phrase//hard/soft///password" into its parts
+func ParseURI(suri string) (phrase, path, password string, err error) {
+	if i := strings.Index(suri, "///"); i >= 0 {
+		suri, password = suri[:i], suri[i+3:]
+	}
+	phrase, path = suri, ""
+	if i := strings.Index(suri, "/"); i >= 0 {
+		phrase, path = suri[:i], suri[i:]
+	}
+	if _, err := ParsePath(path); err != nil {
+		return "", "", "", err
+	}
+	return strings.TrimSpace(phrase), path, password, nil
+}
+
+// Derive returns the key pair at path below the mnemonic, e.g. Derive(mnemonic, "", "//hot//0", signer.Sr25519)
+func Derive(mnemonic, password, path string, crypto signer.CryptoType) (*signer.Keyring, error) {
+	if err := ValidateMnemonic(mnemonic); err != nil {
+		return nil, err
+	}
+	if _, err := ParsePath(path); err != nil {
+		return nil, err
+	}
+	suri := strings.Join(strings.Fields(mnemonic), " ") + path
+	if password != "" {
+		suri += "///" + password
+	}
+	return signer.FromSecret(suri, crypto)
+}
+
+/*
+DerivePublic soft derives an sr25519 public key along path without the secret key, so a watch-only
+service holding the public key of "mnemonic//hot" can compute the addresses of "mnemonic//hot/deposit/42".
+The path must only contain soft junctions.
+*/
+func DerivePublic(publicKey []byte, path string) ([]byte, error) {
+	if len(publicKey) != 32 {
+		return nil, fmt.Errorf("invalid sr25519 public key length %d", len(publicKey))
+	}
+	junctions, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	var b [32]byte
+	copy(b[:], publicKey)
+	pub, err := schnorrkel.NewPublicKey(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sr25519 public key %v", err)
+	}
+	for _, j := range junctions {
+		if j.Hard {
+			return nil, ErrHardJunction
+		}
+		t := merlin.NewTranscript("SchnorrRistrettoHDKD")
+		t.AppendMessage([]byte("sign-bytes"), nil)
+		ek, err := pub.DeriveKey(t, j.ChainCode)
+		if err != nil {
+			return nil, fmt.Errorf("derive error: %v", err)
+		}
+		pub, err = ek.Public()
+		if err != nil {
+			return nil, fmt.Errorf("derive error: %v", err)
+		}
+	}
+	res := pub.Encode()
+	return res[:], nil
+}
@@ -0,0 +1,54 @@
+package keys
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ChainSafe/go-schnorrkel"
+	"github.com/cosmos/go-bip39"
+)
+
+var ErrInvalidMnemonic = errors.New("invalid mnemonic")
+
+// GenerateMnemonic returns a new BIP39 english mnemonic of 12, 15, 18, 21 or 24 words
+func GenerateMnemonic(words int) (string, error) {
+	if words < 12 || words > 24 || words%3 != 0 {
+		return "", fmt.Errorf("invalid mnemonic length %d", words)
+	}
+	entropy, err := bip39.NewEntropy(words / 3 * 32)
+	if err != nil {
+		return "", err
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// ValidateMnemonic checks the words and the checksum of a BIP39 english mnemonic
+func ValidateMnemonic(mnemonic string) error {
+	words := strings.Fields(mnemonic)
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return fmt.Errorf("%w: %d words", ErrInvalidMnemonic, len(words))
+	}
+	if _, err := bip39.MnemonicToByteArray(strings.Join(words, " ")); err != nil {
+		return fmt.Errorf("%w: unknown word or bad checksum", ErrInvalidMnemonic)
+	}
+	return nil
+}
+
+/*
+MiniSecret returns the 32 byte seed substrate derives from a mnemonic: PBKDF2-SHA512 of the
+mnemonic entropy, not of the words as in BIP39, salted with "mnemonic" and the password.
+It is the seed of the root sr25519, ed25519 and ecdsa key pairs.
+*/
+func MiniSecret(mnemonic, password string) ([32]byte, error) {
+	var secret [32]byte
+	if err := ValidateMnemonic(mnemonic); err != nil {
+		return secret, err
+	}
+	seed, err := schnorrkel.SeedFromMnemonic(strings.Join(strings.Fields(mnemonic), " "), password)
+	if err != nil {
+		return secret, fmt.Errorf("%w: %v", ErrInvalidMnemonic, err)
+	}
+	copy(secret[:], seed[:32])
+	return secret, nil
+}
@@ -1,25 +1,89 @@
 package models
 
+import "github.com/DataHighway-DHX/substrate-go/uint128"
+
 type BlockResponse struct {
 	Height     int64                `json:"height"`
 	ParentHash string               `json:"parent_hash"`
 	BlockHash  string               `json:"block_hash"`
-	Timestamp  int64                `json:"timestamp"`
+	Timestamp  *int64               `json:"timestamp"` //unix seconds, nil when the block has no timestamp
 	Extrinsic  []*ExtrinsicResponse `json:"extrinsic"`
 }
 
 type ExtrinsicResponse struct {
-	Type            string `json:"type"`   //Transfer or another
-	Status          string `json:"status"` //success or fail
-	Txid            string `json:"txid"`
-	FromAddress     string `json:"from_address"`
-	ToAddress       string `json:"to_address"`
-	Amount          string `json:"amount"`
-	Fee             string `json:"fee"`
-	Signature       string `json:"signature"`
-	Nonce           int64  `json:"nonce"`
-	Era             string `json:"era"`
-	ExtrinsicIndex  int    `json:"extrinsic_index"`
-	EventIndex      int    `json:"event_index"`
-	ExtrinsicLength int    `json:"extrinsic_length"`
+	Type            string  `json:"type"`   //Transfer or another
+	Status          string  `json:"status"` //success or fail
+	Txid            string  `json:"txid"`
+	Signer          string  `json:"signer"` //SS58 address of the account that signed the extrinsic
+	SignerPublicKey string  `json:"signer_public_key"`
+	CallPath        string  `json:"call_path"`              //e.g. batch_all[2].transfer_keep_alive
+	DecodeError     string  `json:"decode_error,omitempty"` //set when the call could not be decoded, CallPath and Dest are then unknown
+	FromAddress     string  `json:"from_address"`           //SS58
+	FromPublicKey   string  `json:"from_public_key"`
+	ToAddress       string  `json:"to_address"` //SS58
+	ToPublicKey     string  `json:"to_public_key"`
+	DestType        string  `json:"dest_type"` //MultiAddress variant used by the call: Id, Index, Raw, Address20 or Address32
+	Dest            string  `json:"dest"`      //destination as given in the call
+	Amount          string  `json:"amount"`    //planck
+	AmountValue     *Amount `json:"amount_value"`
+	Fee             string  `json:"fee"` //planck
+	FeeValue        *Amount `json:"fee_value"`
+	Signature       string  `json:"signature"`
+	Nonce           int64   `json:"nonce"`
+	Era             string  `json:"era"`
+	ExtrinsicIndex  int     `json:"extrinsic_index"`
+	EventIndex      int     `json:"event_index"`
+	ExtrinsicLength int     `json:"extrinsic_length"`
+}
+
+type Amount struct {
+	Raw       string `json:"raw"` //planck
+	Decimals  int    `json:"decimals"`
+	Symbol    string `json:"symbol"`
+	Formatted string `json:"formatted"` //e.g. 1.5
+}
+
+type ExtrinsicLocation struct {
+	Txid           string `json:"txid"`
+	BlockHash      string `json:"block_hash"`
+	Height         int64  `json:"height"`
+	ExtrinsicIndex int    `json:"extrinsic_index"`
+	Status         string `json:"status"` //success or fail
+	Fee            string `json:"fee"`
+}
+
+type FeeEstimate struct {
+	Weight            uint64  `json:"weight"`     //ref time
+	ProofSize         uint64  `json:"proof_size"` //0 on runtimes with one dimensional weights
+	Class             string  `json:"class"`      //normal, operational or mandatory
+	Length            int     `json:"length"`     //bytes of the signed extrinsic
+	PartialFee        *Amount `json:"partial_fee"`
+	BaseFee           *Amount `json:"base_fee"`
+	LenFee            *Amount `json:"len_fee"`
+	AdjustedWeightFee *Amount `json:"adjusted_weight_fee"`
+	Tip               *Amount `json:"tip"`
+	Total             *Amount `json:"total"` //partial fee plus tip, what the signer pays
+}
+
+type AccountInfo struct {
+	Nonce       uint32          `json:"nonce"`
+	Consumers   uint32          `json:"consumers"`
+	Providers   uint32          `json:"providers"`
+	Sufficients uint32          `json:"sufficients"`
+	Free        uint128.Uint128 `json:"free"`     //planck
+	Reserved    uint128.Uint128 `json:"reserved"` //planck
+	MiscFrozen  uint128.Uint128 `json:"misc_frozen"`
+	FeeFrozen   uint128.Uint128 `json:"fee_frozen"`
+}
+
+// Transferable is the free balance that is not frozen
+func (ai *AccountInfo) Transferable() uint128.Uint128 {
+	frozen := ai.MiscFrozen
+	if ai.FeeFrozen.Cmp(frozen) > 0 {
+		frozen = ai.FeeFrozen
+	}
+	if ai.Free.Cmp(frozen) <= 0 {
+		return uint128.Zero
+	}
+	return ai.Free.Sub(frozen)
 }
@@ -0,0 +1,99 @@
+package signer
+
+import (
+	"fmt"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	secp256k1 "github.com/ethereum/go-ethereum/crypto"
+	"github.com/vedhavyas/go-subkey"
+	"github.com/vedhavyas/go-subkey/ecdsa"
+	"github.com/vedhavyas/go-subkey/ed25519"
+	"github.com/vedhavyas/go-subkey/sr25519"
+)
+
+// Keyring is a Signer holding the key pair in memory
+type Keyring struct {
+	kp     subkey.KeyPair
+	crypto CryptoType
+}
+
+// FromSecret derives a key pair from a secret URI: a mnemonic or 0x seed, optionally followed by a derivation path
+func FromSecret(secret string, crypto CryptoType) (*Keyring, error) {
+	scheme, err := scheme(crypto)
+	if err != nil {
+		return nil, err
+	}
+	kp, err := subkey.DeriveKeyPair(scheme, secret)
+	if err != nil {
+		return nil, fmt.Errorf("can't derive %s key pair %v", crypto, err)
+	}
+	return &Keyring{kp: kp, crypto: crypto}, nil
+}
+
+// Generate creates a random key pair, secret is its 0x seed to back it up or import it into a Keystore
+func Generate(crypto CryptoType) (kr *Keyring, secret string, err error) {
+	scheme, err := scheme(crypto)
+	if err != nil {
+		return nil, "", err
+	}
+	kp, err := scheme.Generate()
+	if err != nil {
+		return nil, "", fmt.Errorf("can't generate %s key pair %v", crypto, err)
+	}
+	return &Keyring{kp: kp, crypto: crypto}, types.HexEncodeToString(kp.Seed()), nil
+}
+
+// FromSeed creates a key pair from a 32 byte seed
+func FromSeed(seed []byte, crypto CryptoType) (*Keyring, error) {
+	scheme, err := scheme(crypto)
+	if err != nil {
+		return nil, err
+	}
+	kp, err := scheme.FromSeed(seed)
+	if err != nil {
+		return nil, fmt.Errorf("can't create %s key pair %v", crypto, err)
+	}
+	return &Keyring{kp: kp, crypto: crypto}, nil
+}
+
+func scheme(crypto CryptoType) (subkey.Scheme, error) {
+	switch crypto {
+	case Ed25519:
+		return ed25519.Scheme{}, nil
+	case Sr25519:
+		return sr25519.Scheme{}, nil
+	case Ecdsa:
+		return ecdsaScheme{}, nil
+	}
+	return nil, fmt.Errorf("unsupported crypto type %s", crypto)
+}
+
+// ecdsaScheme refuses seeds that are not a valid secp256k1 secret, go-subkey panics on a zero seed or one not below the curve order
+type ecdsaScheme struct {
+	ecdsa.Scheme
+}
+
+func (s ecdsaScheme) FromSeed(seed []byte) (subkey.KeyPair, error) {
+	_, err := secp256k1.ToECDSA(seed)
+	if err != nil {
+		return nil, err
+	}
+	return s.Scheme.FromSeed(seed)
+}
+
+// PublicKey returns the 32 byte ed25519 or sr25519 key, or the 33 byte compressed ecdsa key
+func (k *Keyring) PublicKey() []byte {
+	return k.kp.Public()
+}
+
+func (k *Keyring) CryptoType() CryptoType {
+	return k.crypto
+}
+
+func (k *Keyring) Sign(payload []byte) ([]byte, error) {
+	return k.kp.Sign(payload)
+}
+
+func (k *Keyring) Verify(payload, sig []byte) bool {
+	return k.kp.Verify(payload, sig)
+}
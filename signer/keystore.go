@@ -0,0 +1,170 @@
+package signer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+var ErrWrongPassword = errors.New("wrong keystore password")
+
+// scrypt parameters of new key files
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+/*
+Keystore keeps secrets in a directory, one JSON file per public key. The secret URI is sealed
+with xsalsa20-poly1305 under a key derived from the password with scrypt, and is only
+decrypted in memory by Unlock.
+*/
+type Keystore struct {
+	Dir string
+}
+
+type keyFile struct {
+	PublicKey  string `json:"public_key"`
+	Crypto     string `json:"crypto"`
+	ScryptN    int    `json:"scrypt_n"`
+	ScryptR    int    `json:"scrypt_r"`
+	ScryptP    int    `json:"scrypt_p"`
+	Salt       string `json:"salt"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+func NewKeystore(dir string) (*Keystore, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("can't create keystore dir %v", err)
+	}
+	return &Keystore{Dir: dir}, nil
+}
+
+func (ks *Keystore) path(publicKey []byte) string {
+	return filepath.Join(ks.Dir, types.HexEncodeToString(publicKey)+".json")
+}
+
+// Import encrypts a secret URI with password and stores it, returning the public key of the account
+func (ks *Keystore) Import(secret string, crypto CryptoType, password string) ([]byte, error) {
+	kr, err := FromSecret(secret, crypto)
+	if err != nil {
+		return nil, err
+	}
+	var salt [32]byte
+	var nonce [24]byte
+	if _, err := rand.Read(salt[:]); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, err
+	}
+	key, err := deriveKey(password, salt[:], scryptN, scryptR, scryptP)
+	if err != nil {
+		return nil, err
+	}
+	kf := keyFile{
+		PublicKey:  types.HexEncodeToString(kr.PublicKey()),
+		Crypto:     crypto.String(),
+		ScryptN:    scryptN,
+		ScryptR:    scryptR,
+		ScryptP:    scryptP,
+		Salt:       types.HexEncodeToString(salt[:]),
+		Nonce:      types.HexEncodeToString(nonce[:]),
+		Ciphertext: types.HexEncodeToString(secretbox.Seal(nil, []byte(secret), &nonce, key)),
+	}
+	data, err := json.MarshalIndent(kf, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	err = os.WriteFile(ks.path(kr.PublicKey()), data, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("can't write key file %v", err)
+	}
+	return kr.PublicKey(), nil
+}
+
+// Accounts returns the public keys stored in the keystore
+func (ks *Keystore) Accounts() ([][]byte, error) {
+	files, err := filepath.Glob(filepath.Join(ks.Dir, "0x*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var keys [][]byte
+	for _, f := range files {
+		pub, err := types.HexDecodeString(strings.TrimSuffix(filepath.Base(f), ".json"))
+		if err != nil {
+			continue
+		}
+		keys = append(keys, pub)
+	}
+	return keys, nil
+}
+
+// Unlock decrypts the secret of publicKey and returns a Signer for it
+func (ks *Keystore) Unlock(publicKey []byte, password string) (*Keyring, error) {
+	data, err := os.ReadFile(ks.path(publicKey))
+	if err != nil {
+		return nil, fmt.Errorf("can't read key file %v", err)
+	}
+	var kf keyFile
+	err = json.Unmarshal(data, &kf)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key file %v", err)
+	}
+	crypto, err := ParseCryptoType(kf.Crypto)
+	if err != nil {
+		return nil, err
+	}
+	salt, err := types.HexDecodeString(kf.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key file salt %v", err)
+	}
+	nonceBytes, err := types.HexDecodeString(kf.Nonce)
+	if err != nil || len(nonceBytes) != 24 {
+		return nil, fmt.Errorf("invalid key file nonce")
+	}
+	ciphertext, err := types.HexDecodeString(kf.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key file ciphertext %v", err)
+	}
+	key, err := deriveKey(password, salt, kf.ScryptN, kf.ScryptR, kf.ScryptP)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [24]byte
+	copy(nonce[:], nonceBytes)
+	secret, ok := secretbox.Open(nil, ciphertext, &nonce, key)
+	if !ok {
+		return nil, ErrWrongPassword
+	}
+	kr, err := FromSecret(string(secret), crypto)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(kr.PublicKey(), publicKey) {
+		return nil, fmt.Errorf("key file secret does not belong to %s", types.HexEncodeToString(publicKey))
+	}
+	return kr, nil
+}
+
+func deriveKey(password string, salt []byte, n, r, p int) (*[32]byte, error) {
+	b, err := scrypt.Key([]byte(password), salt, n, r, p, 32)
+	if err != nil {
+		return nil, fmt.Errorf("scrypt error: %v", err)
+	}
+	var key [32]byte
+	copy(key[:], b)
+	return &key, nil
+}
@@ -0,0 +1,209 @@
+package signer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/DataHighway-DHX/substrate-go/ss58"
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+var (
+	pkcs8Header  = []byte{48, 83, 2, 1, 1, 48, 5, 6, 3, 43, 101, 112, 4, 34, 4, 32}
+	pkcs8Divider = []byte{161, 35, 3, 33, 0}
+)
+
+// length of the scrypt salt and N, p, r parameters in front of the encrypted secret
+const pjsScryptLength = 32 + 3*4
+
+/*
+PolkadotJSAccount is an account exported from polkadot-js (apps or extension). The secret is
+PKCS8 encoded and sealed with xsalsa20-poly1305 under a scrypt derived key.
+*/
+type PolkadotJSAccount struct {
+	Encoded  string `json:"encoded"` // base64
+	Encoding struct {
+		Content []string `json:"content"` // ["pkcs8", "sr25519"]
+		Type    []string `json:"type"`    // ["scrypt", "xsalsa20-poly1305"]
+		Version string   `json:"version"`
+	} `json:"encoding"`
+	Address string                 `json:"address"`
+	Meta    map[string]interface{} `json:"meta"`
+}
+
+// ImportPolkadotJS decrypts a polkadot-js account export into a Signer
+func ImportPolkadotJS(data []byte, password string) (*Keyring, error) {
+	var acc PolkadotJSAccount
+	err := json.Unmarshal(data, &acc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid polkadot-js json %v", err)
+	}
+	if len(acc.Encoding.Content) < 2 || acc.Encoding.Content[0] != "pkcs8" {
+		return nil, fmt.Errorf("unsupported polkadot-js content %v", acc.Encoding.Content)
+	}
+	crypto, err := ParseCryptoType(acc.Encoding.Content[1])
+	if err != nil {
+		return nil, err
+	}
+	if len(acc.Encoding.Type) != 2 || acc.Encoding.Type[0] != "scrypt" || acc.Encoding.Type[1] != "xsalsa20-poly1305" {
+		return nil, fmt.Errorf("unsupported polkadot-js encryption %v, version %s", acc.Encoding.Type, acc.Encoding.Version)
+	}
+	encoded, err := base64.StdEncoding.DecodeString(acc.Encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid polkadot-js encoded %v", err)
+	}
+	if len(encoded) < pjsScryptLength+24+secretbox.Overhead {
+		return nil, errors.New("polkadot-js encoded secret is too short")
+	}
+	salt := encoded[:32]
+	n := binary.LittleEndian.Uint32(encoded[32:])
+	p := binary.LittleEndian.Uint32(encoded[36:])
+	r := binary.LittleEndian.Uint32(encoded[40:])
+	key, err := deriveKey(password, salt, int(n), int(r), int(p))
+	if err != nil {
+		return nil, err
+	}
+	var nonce [24]byte
+	copy(nonce[:], encoded[pjsScryptLength:])
+	plain, ok := secretbox.Open(nil, encoded[pjsScryptLength+24:], &nonce, key)
+	if !ok {
+		return nil, ErrWrongPassword
+	}
+	return decodePKCS8(plain, crypto)
+}
+
+func decodePKCS8(b []byte, crypto CryptoType) (*Keyring, error) {
+	if !bytes.HasPrefix(b, pkcs8Header) {
+		return nil, errors.New("invalid pkcs8 header")
+	}
+	b = b[len(pkcs8Header):]
+	// 64 byte secret keys for sr25519 and ed25519, 32 byte ones for ecdsa
+	secretLen := 64
+	if len(b) < secretLen+len(pkcs8Divider) || !bytes.Equal(b[secretLen:secretLen+len(pkcs8Divider)], pkcs8Divider) {
+		secretLen = 32
+	}
+	if len(b) < secretLen+len(pkcs8Divider) || !bytes.Equal(b[secretLen:secretLen+len(pkcs8Divider)], pkcs8Divider) {
+		return nil, errors.New("invalid pkcs8 divider")
+	}
+	secret, pub := b[:secretLen], b[secretLen+len(pkcs8Divider):]
+
+	var seed []byte
+	switch {
+	case crypto == Sr25519 && secretLen == 64:
+		// ed25519 expanded form, the scalar is stored multiplied by the cofactor
+		seed = append(divideByCofactor(secret[:32]), secret[32:]...)
+	case crypto == Ed25519 && secretLen == 64:
+		seed = secret[:32]
+	case crypto == Ecdsa && secretLen == 32:
+		seed = secret
+	default:
+		return nil, fmt.Errorf("invalid %s pkcs8 secret length %d", crypto, secretLen)
+	}
+	kr, err := FromSeed(seed, crypto)
+	if err != nil {
+		return nil, err
+	}
+	if len(pub) == 0 || !bytes.HasPrefix(kr.PublicKey(), pub) {
+		return nil, errors.New("pkcs8 public key does not match the secret key")
+	}
+	return kr, nil
+}
+
+// ExportPolkadotJS encrypts a key pair into the polkadot-js account format, address uses the network prefix
+func ExportPolkadotJS(kr *Keyring, password string, network uint16, name string) ([]byte, error) {
+	plain, err := encodePKCS8(kr)
+	if err != nil {
+		return nil, err
+	}
+	address, err := ss58.EncodeWithNetwork(AccountId(kr), network)
+	if err != nil {
+		return nil, err
+	}
+	var salt [32]byte
+	var nonce [24]byte
+	if _, err := rand.Read(salt[:]); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, err
+	}
+	key, err := deriveKey(password, salt[:], scryptN, scryptR, scryptP)
+	if err != nil {
+		return nil, err
+	}
+	encoded := make([]byte, pjsScryptLength, pjsScryptLength+24+len(plain)+secretbox.Overhead)
+	copy(encoded, salt[:])
+	binary.LittleEndian.PutUint32(encoded[32:], scryptN)
+	binary.LittleEndian.PutUint32(encoded[36:], scryptP)
+	binary.LittleEndian.PutUint32(encoded[40:], scryptR)
+	encoded = append(encoded, nonce[:]...)
+	encoded = secretbox.Seal(encoded, plain, &nonce, key)
+
+	var acc PolkadotJSAccount
+	acc.Encoded = base64.StdEncoding.EncodeToString(encoded)
+	acc.Encoding.Content = []string{"pkcs8", kr.CryptoType().String()}
+	acc.Encoding.Type = []string{"scrypt", "xsalsa20-poly1305"}
+	acc.Encoding.Version = "3"
+	acc.Address = address
+	acc.Meta = map[string]interface{}{"name": name, "whenCreated": time.Now().UnixNano() / int64(time.Millisecond)}
+	return json.Marshal(acc)
+}
+
+func encodePKCS8(kr *Keyring) ([]byte, error) {
+	seed := kr.kp.Seed()
+	var secret []byte
+	switch kr.crypto {
+	case Sr25519:
+		switch len(seed) {
+		case 32:
+			// mini secret, expanded the way schnorrkel does before the scalar is divided by the cofactor
+			h := sha512.Sum512(seed)
+			h[0] &= 248
+			h[31] &= 63
+			h[31] |= 64
+			secret = h[:]
+		case 64:
+			secret = append(multiplyByCofactor(seed[:32]), seed[32:]...)
+		default:
+			return nil, errors.New("soft derived sr25519 key pairs can't be exported")
+		}
+	case Ed25519:
+		secret = append(append([]byte{}, seed...), kr.PublicKey()...)
+	case Ecdsa:
+		secret = seed
+	default:
+		return nil, fmt.Errorf("unsupported crypto type %s", kr.crypto)
+	}
+	out := append(append([]byte{}, pkcs8Header...), secret...)
+	out = append(out, pkcs8Divider...)
+	return append(out, kr.PublicKey()...), nil
+}
+
+// divideByCofactor divides a little endian scalar by 8
+func divideByCofactor(s []byte) []byte {
+	out := make([]byte, len(s))
+	var low byte
+	for i := len(s) - 1; i >= 0; i-- {
+		out[i] = s[i]>>3 | low
+		low = s[i] << 5
+	}
+	return out
+}
+
+// multiplyByCofactor multiplies a little endian scalar by 8
+func multiplyByCofactor(s []byte) []byte {
+	out := make([]byte, len(s))
+	var high byte
+	for i := range s {
+		out[i] = s[i]<<3 | high
+		high = s[i] >> 5
+	}
+	return out
+}
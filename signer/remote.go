@@ -0,0 +1,130 @@
+package signer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+/*
+Remote is a Signer backed by a signing service. Each Sign is one request
+
+	POST <URL>/sign {"public_key": "0x..", "crypto": "sr25519", "payload": "0x.."}
+
+answered with {"signature": "0x.."}, or with {"error": ".."} and a non 2xx status.
+Server implements the protocol for local testing.
+*/
+type Remote struct {
+	URL    string
+	Client *http.Client
+	pub    []byte
+	crypto CryptoType
+}
+
+type signRequest struct {
+	PublicKey string `json:"public_key"`
+	Crypto    string `json:"crypto"`
+	Payload   string `json:"payload"`
+}
+
+type signResponse struct {
+	Signature string `json:"signature,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+func NewRemote(url string, publicKey []byte, crypto CryptoType) *Remote {
+	return &Remote{URL: strings.TrimSuffix(url, "/"), Client: http.DefaultClient, pub: publicKey, crypto: crypto}
+}
+
+func (r *Remote) PublicKey() []byte {
+	return r.pub
+}
+
+func (r *Remote) CryptoType() CryptoType {
+	return r.crypto
+}
+
+func (r *Remote) Sign(payload []byte) ([]byte, error) {
+	body, err := json.Marshal(signRequest{
+		PublicKey: types.HexEncodeToString(r.pub),
+		Crypto:    r.crypto.String(),
+		Payload:   types.HexEncodeToString(payload),
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.Client.Post(r.URL+"/sign", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("remote signer request error: %v", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer response error: %v", err)
+	}
+	var res signResponse
+	err = json.Unmarshal(data, &res)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer response error: %s %v", resp.Status, err)
+	}
+	if resp.StatusCode/100 != 2 || res.Error != "" {
+		return nil, fmt.Errorf("remote signer error: %s %s", resp.Status, res.Error)
+	}
+	sig, err := types.HexDecodeString(res.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote signature %v", err)
+	}
+	return sig, nil
+}
+
+// Server answers Remote sign requests with the signers it holds
+type Server struct {
+	signers map[string]Signer
+}
+
+func NewServer(signers ...Signer) *Server {
+	s := &Server{signers: make(map[string]Signer)}
+	for _, sg := range signers {
+		s.signers[types.HexEncodeToString(sg.PublicKey())] = sg
+	}
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	reply := func(status int, res signResponse) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(res)
+	}
+	if r.Method != http.MethodPost || r.URL.Path != "/sign" {
+		reply(http.StatusNotFound, signResponse{Error: "not found"})
+		return
+	}
+	var req signRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		reply(http.StatusBadRequest, signResponse{Error: err.Error()})
+		return
+	}
+	sg, ok := s.signers[strings.ToLower(req.PublicKey)]
+	if !ok || sg.CryptoType().String() != req.Crypto {
+		reply(http.StatusNotFound, signResponse{Error: "unknown key " + req.PublicKey})
+		return
+	}
+	payload, err := types.HexDecodeString(req.Payload)
+	if err != nil {
+		reply(http.StatusBadRequest, signResponse{Error: "invalid payload"})
+		return
+	}
+	sig, err := sg.Sign(payload)
+	if err != nil {
+		reply(http.StatusInternalServerError, signResponse{Error: err.Error()})
+		return
+	}
+	reply(http.StatusOK, signResponse{Signature: types.HexEncodeToString(sig)})
+}
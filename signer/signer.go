@@ -0,0 +1,62 @@
+package signer
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// CryptoType is the signature scheme of a key, the values are the MultiSignature variant indexes
+type CryptoType uint8
+
+const (
+	Ed25519 CryptoType = iota
+	Sr25519
+	Ecdsa
+)
+
+func (t CryptoType) String() string {
+	switch t {
+	case Ed25519:
+		return "ed25519"
+	case Sr25519:
+		return "sr25519"
+	case Ecdsa:
+		return "ecdsa"
+	}
+	return fmt.Sprintf("CryptoType(%d)", uint8(t))
+}
+
+func ParseCryptoType(s string) (CryptoType, error) {
+	switch strings.ToLower(s) {
+	case "ed25519":
+		return Ed25519, nil
+	case "sr25519":
+		return Sr25519, nil
+	case "ecdsa":
+		return Ecdsa, nil
+	}
+	return 0, fmt.Errorf("unknown crypto type %q", s)
+}
+
+/*
+Signer signs transactions for one account without exposing its secret, so keys can stay in a
+keystore or in a separate signing service. Sign gets the extrinsic signing payload, payloads
+longer than 256 bytes are blake2_256 hashed by the caller first.
+*/
+type Signer interface {
+	PublicKey() []byte
+	CryptoType() CryptoType
+	Sign(payload []byte) ([]byte, error)
+}
+
+// AccountId returns the account of a signer, the blake2_256 hash of the compressed public key for ecdsa
+func AccountId(s Signer) []byte {
+	pub := s.PublicKey()
+	if s.CryptoType() == Ecdsa {
+		h := blake2b.Sum256(pub)
+		return h[:]
+	}
+	return pub
+}
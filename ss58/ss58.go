@@ -1,6 +1,7 @@
 package ss58
 
 import (
+	"bytes"
 	"encoding/hex"
 	"errors"
 
@@ -23,6 +24,56 @@ func Encode(publicKeyHash []byte, prefix []byte) (string, error) {
 	return address, nil
 }
 
+// EncodeWithNetwork encodes an account id with a network id, using the two byte prefix form for ids from 64 to 16383
+func EncodeWithNetwork(publicKeyHash []byte, network uint16) (string, error) {
+	prefix, err := NetworkPrefix(network)
+	if err != nil {
+		return "", err
+	}
+	return Encode(publicKeyHash, prefix)
+}
+
+// NetworkPrefix returns the address prefix bytes of a network id
+func NetworkPrefix(network uint16) ([]byte, error) {
+	switch {
+	case network < 64:
+		return []byte{byte(network)}, nil
+	case network < 16384:
+		first := byte((network&0xfc)>>2) | 0x40
+		second := byte(network>>8) | byte((network&0x03)<<6)
+		return []byte{first, second}, nil
+	}
+	return nil, errors.New("network id is out of range")
+}
+
+/*
+Decode an address into its account id and network id. One and two byte prefixes are
+supported and the checksum is verified.
+*/
+func DecodeWithNetwork(address string) (publicKeyHash []byte, network uint16, err error) {
+	data := base58.Decode(address)
+	if len(data) < 35 {
+		return nil, 0, errors.New("base58 decode error")
+	}
+	prefixLen := 1
+	if data[0]&0x40 != 0 {
+		prefixLen = 2
+	}
+	if data[0] >= 128 || len(data) != prefixLen+32+2 {
+		return nil, 0, errors.New("invalid address length or prefix")
+	}
+	if prefixLen == 1 {
+		network = uint16(data[0])
+	} else {
+		network = uint16(data[0]&0x3f)<<2 | uint16(data[1]>>6) | uint16(data[1]&0x3f)<<8
+	}
+	ck := blake2b.Sum512(appendBytes(append([]byte{}, SSPrefix...), data[:prefixLen+32]))
+	if !bytes.Equal(ck[:2], data[prefixLen+32:]) {
+		return nil, 0, errors.New("checksum valid error")
+	}
+	return data[prefixLen : prefixLen+32], network, nil
+}
+
 func EncodeByPubHex(publicHex string, prefix []byte) (string, error) {
 	publicKeyHash, err := hex.DecodeString(publicHex)
 	if err != nil {
@@ -0,0 +1,48 @@
+package test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/DataHighway-DHX/substrate-go/client"
+	"github.com/DataHighway-DHX/substrate-go/uint128"
+)
+
+func Test_GetAccount(t *testing.T) {
+	c := newMockNode(t, map[string]interface{}{
+		"state_getStorage": "0x" +
+			"05000000" + "00000000" + "01000000" + "00000000" +
+			// free 2^70, reserved 7, misc frozen 1000, fee frozen 0
+			"00000000000000004000000000000000" + "07000000000000000000000000000000" +
+			"e8030000000000000000000000000000" + "00000000000000000000000000000000",
+	})
+	ai, err := c.GetAccount(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	free := uint128.From64(1).Lsh(70)
+	if ai.Nonce != 5 || ai.Providers != 1 || !ai.Free.Equals(free) || !ai.Reserved.Equals64(7) || !ai.MiscFrozen.Equals64(1000) {
+		t.Fatalf("unexpected account %+v", ai)
+	}
+	if !ai.Transferable().Equals(free.Sub64(1000)) {
+		t.Fatalf("unexpected transferable %s", ai.Transferable())
+	}
+}
+
+func Test_TransferAbove64Bits(t *testing.T) {
+	c := newMockNode(t, nil)
+	value, err := uint128.FromString("20000000000000000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := c.NewTransferCall(client.Transfer{Kind: client.TransferKeepAlive, Dest: "0x" + strings.Repeat("01", 32), Value: value})
+	if err != nil {
+		t.Fatal(err)
+	}
+	amount := ca.Args[33:]
+	want := []byte{0x17, 0x00, 0x00, 0xd0, 0x13, 0x09, 0x46, 0x8e, 0x15, 0x01}
+	if !bytes.Equal(amount, want) {
+		t.Fatalf("unexpected compact amount %x", amount)
+	}
+}
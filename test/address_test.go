@@ -0,0 +1,114 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DataHighway-DHX/substrate-go/client"
+	"github.com/DataHighway-DHX/substrate-go/ss58"
+	"github.com/DataHighway-DHX/substrate-go/uint128"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/vedhavyas/go-subkey"
+)
+
+func Test_EncodeWithNetwork(t *testing.T) {
+	alice := signature.TestKeyringPairAlice
+
+	addr, err := ss58.EncodeWithNetwork(alice.PublicKey, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr != alice.Address {
+		t.Fatalf("got %s, want %s", addr, alice.Address)
+	}
+
+	addr, err = ss58.EncodeWithNetwork(alice.PublicKey, 33)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := subkey.SS58Address(alice.PublicKey, 33)
+	if addr != want {
+		t.Fatalf("got %s, want %s", addr, want)
+	}
+
+	prefix, err := ss58.NetworkPrefix(1284)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prefix) != 2 || prefix[0]&0xc0 != 0x40 {
+		t.Fatalf("unexpected two byte prefix %x", prefix)
+	}
+}
+
+func Test_FormatMultiAddress(t *testing.T) {
+	alice := signature.TestKeyringPairAlice
+
+	kind, addr, err := client.FormatMultiAddress(types.NewMultiAddressFromAccountID(alice.PublicKey), 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kind != "Id" || addr != alice.Address {
+		t.Fatalf("got %s %s", kind, addr)
+	}
+
+	kind, addr, err = client.FormatMultiAddress(types.MultiAddress{IsIndex: true, AsIndex: 7}, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kind != "Index" || addr != "7" {
+		t.Fatalf("got %s %s", kind, addr)
+	}
+}
+
+func Test_DecodeWithNetwork(t *testing.T) {
+	alice := signature.TestKeyringPairAlice
+	for _, network := range []uint16{0, 42, 63, 64, 1284, 16383} {
+		addr, err := ss58.EncodeWithNetwork(alice.PublicKey, network)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub, got, err := ss58.DecodeWithNetwork(addr)
+		if err != nil {
+			t.Fatalf("network %d: %v", network, err)
+		}
+		if got != network || string(pub) != string(alice.PublicKey) {
+			t.Fatalf("network %d: decoded %d %x", network, got, pub)
+		}
+	}
+	// last character changed, checksum no longer matches
+	if _, _, err := ss58.DecodeWithNetwork("5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKutQZ"); err == nil {
+		t.Fatal("expected checksum error")
+	}
+}
+
+func Test_ParseAddress(t *testing.T) {
+	c := newMockNode(t, nil)
+	alice := signature.TestKeyringPairAlice
+	polkadot, _ := ss58.EncodeWithNetwork(alice.PublicKey, 0)
+
+	for _, addr := range []string{alice.Address, types.HexEncodeToString(alice.PublicKey)} {
+		ma, err := c.ParseAddress(addr, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ma.IsID || string(ma.AsID[:]) != string(alice.PublicKey) {
+			t.Fatalf("%s: unexpected address %+v", addr, ma)
+		}
+	}
+	if _, err := c.ParseAddress(polkadot, false); !errors.Is(err, client.ErrNetworkMismatch) {
+		t.Fatalf("expected network mismatch, got %v", err)
+	}
+	if _, err := c.ParseAddress(polkadot, true); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := c.NewTransferCall(client.Transfer{Dest: polkadot, Value: uint128.From64(1)})
+	if !errors.Is(err, client.ErrNetworkMismatch) {
+		t.Fatalf("expected transfer to be refused, got %v", err)
+	}
+	_, err = c.NewTransferCall(client.Transfer{Dest: polkadot, Value: uint128.From64(1), AllowOtherNetwork: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
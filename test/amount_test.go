@@ -0,0 +1,36 @@
+package test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/DataHighway-DHX/substrate-go/client"
+)
+
+func Test_FormatAmount(t *testing.T) {
+	raw, _ := new(big.Int).SetString("1500000000000000000", 10)
+	if s := client.FormatAmount(raw, 18); s != "1.5" {
+		t.Fatalf("got %s", s)
+	}
+	if s := client.FormatAmount(big.NewInt(1), 18); s != "0.000000000000000001" {
+		t.Fatalf("got %s", s)
+	}
+}
+
+func Test_ParseAmount(t *testing.T) {
+	want, _ := new(big.Int).SetString("1500000000000000000", 10)
+	for _, s := range []string{"1.5", "1.5 DHX", " 1.50 dhx "} {
+		got, err := client.ParseAmount(s, 18, "DHX")
+		if err != nil {
+			t.Fatalf("%q: %v", s, err)
+		}
+		if got.Cmp(want) != 0 {
+			t.Fatalf("%q: got %s", s, got)
+		}
+	}
+	for _, s := range []string{"1.5 DOT", "-1", "0.0000000000000000001", "abc", "1 2 3"} {
+		if _, err := client.ParseAmount(s, 18, "DHX"); err == nil {
+			t.Fatalf("%q: expected error", s)
+		}
+	}
+}
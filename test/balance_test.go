@@ -0,0 +1,210 @@
+package test
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/DataHighway-DHX/substrate-go/client"
+	"github.com/DataHighway-DHX/substrate-go/signer"
+	"github.com/DataHighway-DHX/substrate-go/uint128"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+func Test_CheckTransfer(t *testing.T) {
+	alice := signature.TestKeyringPairAlice
+	free, frozen := make([]byte, 16), make([]byte, 16)
+	uint128.From64(1e15).PutBytes(free)
+	submitted := false
+	c := newMockNode(t, map[string]interface{}{
+		"state_getStorage": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			var key string
+			json.Unmarshal(params[0], &key)
+			if !strings.HasSuffix(key, hex.EncodeToString(alice.PublicKey)) {
+				return nil, nil
+			}
+			return "0x" + "05000000" + "00000000" + "01000000" + "00000000" +
+				hex.EncodeToString(free) + strings.Repeat("00", 16) + hex.EncodeToString(frozen) + hex.EncodeToString(frozen), nil
+		}),
+		"payment_queryInfo": map[string]interface{}{
+			"weight": map[string]interface{}{"refTime": 195000000, "proofSize": 0},
+			"class":  "Normal", "partialFee": "153000000",
+		},
+		"payment_queryFeeDetails": map[string]interface{}{"inclusionFee": nil},
+		"author_submitExtrinsic": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			submitted = true
+			return testBlockHash, nil
+		}),
+	})
+	ed, err := c.ExistentialDeposit()
+	if err != nil || !ed.Equals64(1e14) {
+		t.Fatalf("unexpected existential deposit %s %v", ed, err)
+	}
+
+	from, _ := signer.FromSecret(alice.URI, signer.Sr25519)
+	dest := "0x" + strings.Repeat("01", 32)
+	cases := []struct {
+		kind  client.TransferKind
+		value uint64
+		err   error
+		reaps bool
+	}{
+		{client.TransferKeepAlive, 1e15, client.ErrInsufficientBalance, false},
+		{client.TransferKeepAlive, 95e13, client.ErrReapsSender, false},
+		{client.TransferAllowDeath, 95e13, nil, true},
+		{client.TransferKeepAlive, 1e12, client.ErrBelowExistentialDeposit, false},
+		{client.TransferKeepAlive, 5e14, nil, false},
+	}
+	for i, tc := range cases {
+		tr := client.Transfer{Kind: tc.kind, Dest: dest, Value: uint128.From64(tc.value)}
+		ext, err := c.SignTransfer(from, tr, uint128.Zero)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check, err := c.CheckTransfer(ext, tr)
+		if !errors.Is(err, tc.err) {
+			t.Fatalf("case %d: expected %v, got %v", i, tc.err, err)
+		}
+		var balanceErr *client.BalanceError
+		if tc.err != nil && !errors.As(err, &balanceErr) {
+			t.Fatalf("case %d: expected a *BalanceError, got %T", i, err)
+		}
+		if tc.err == nil && (check.ReapsSender != nil) != tc.reaps {
+			t.Fatalf("case %d: unexpected check %+v", i, check)
+		}
+	}
+
+	// the transfer path refuses failing transfers and warns about reaping ones
+	c.CheckBalances = true
+	var warnings []error
+	c.OnWarning = func(err error) { warnings = append(warnings, err) }
+	_, err = c.AuthorTransfer(from, client.Transfer{Kind: client.TransferKeepAlive, Dest: dest, Value: uint128.From64(1e15)}, uint128.Zero)
+	if !errors.Is(err, client.ErrInsufficientBalance) || submitted {
+		t.Fatalf("expected the transfer to be refused, got %v", err)
+	}
+	_, err = c.AuthorTransfer(from, client.Transfer{Kind: client.TransferAllowDeath, Dest: dest, Value: uint128.From64(95e13)}, uint128.Zero)
+	if err != nil || !submitted {
+		t.Fatalf("expected the transfer to be submitted, got %v", err)
+	}
+	if len(warnings) != 1 || !errors.Is(warnings[0], client.ErrReapsSender) {
+		t.Fatalf("expected a reap warning, got %v", warnings)
+	}
+
+	// an amount that overflows once the fee is added
+	huge := client.Transfer{Kind: client.TransferAllowDeath, Dest: dest, Value: uint128.New(math.MaxUint64-10, math.MaxUint64)}
+	ext, err := c.SignTransfer(from, huge, uint128.Zero)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CheckTransfer(ext, huge); !errors.Is(err, client.ErrInsufficientBalance) {
+		t.Fatalf("expected ErrInsufficientBalance for an overflowing amount, got %v", err)
+	}
+
+	// half of the free balance is frozen
+	uint128.From64(5e14).PutBytes(frozen)
+	submitted = false
+	tr := client.Transfer{Kind: client.TransferAllowDeath, Dest: dest, Value: uint128.From64(6e14)}
+	ext, err = c.SignTransfer(from, tr, uint128.Zero)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var balanceErr *client.BalanceError
+	if _, err := c.CheckTransfer(ext, tr); !errors.As(err, &balanceErr) || !errors.Is(err, client.ErrInsufficientBalance) ||
+		!balanceErr.Available.Equals64(5e14) {
+		t.Fatalf("expected the frozen funds to be unavailable, got %v", err)
+	}
+
+	// batches are checked with the sum of their transfers
+	batch := []client.Transfer{
+		{Kind: client.TransferAllowDeath, Dest: dest, Value: uint128.From64(3e14)},
+		{Kind: client.TransferAllowDeath, Dest: dest, Value: uint128.From64(3e14)},
+	}
+	_, err = c.BatchTransfer(context.Background(), from, batch, client.BatchAll, uint128.Zero, client.UntilInBlock)
+	if !errors.Is(err, client.ErrInsufficientBalance) || submitted {
+		t.Fatalf("expected the batch to be refused, got %v", err)
+	}
+	batch[1].Value = uint128.From64(1e14)
+	check, err := c.CheckBatch(mustSignBatch(t, c, from, batch), batch)
+	if err != nil || !check.Amount.Equals64(4e14) {
+		t.Fatalf("unexpected batch check %+v %v", check, err)
+	}
+}
+
+func mustSignBatch(t *testing.T, c *client.Client, from signer.Signer, transfers []client.Transfer) types.Extrinsic {
+	chunks, err := c.NewBatchCalls(transfers, client.BatchAll, signer.AccountId(from), from.CryptoType())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ext, err := c.SignCall(from, chunks[0].Call, uint128.Zero)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ext
+}
+
+func Test_CheckProxyTransfer(t *testing.T) {
+	// charlie's funds are moved by its proxy alice, who pays the fee
+	balances := map[string]uint64{hex.EncodeToString(charliePub): 1e15, hex.EncodeToString(alicePub): 1e9}
+	submitted := false
+	c := newMockNode(t, map[string]interface{}{
+		"state_getStorage": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			var key string
+			json.Unmarshal(params[0], &key)
+			for account, balance := range balances {
+				if strings.HasSuffix(key, account) {
+					free := make([]byte, 16)
+					uint128.From64(balance).PutBytes(free)
+					return "0x" + "05000000" + "00000000" + "01000000" + "00000000" + hex.EncodeToString(free) + strings.Repeat("00", 48), nil
+				}
+			}
+			return nil, nil
+		}),
+		"payment_queryInfo":       map[string]interface{}{"weight": 195000000, "class": "Normal", "partialFee": "153000000"},
+		"payment_queryFeeDetails": map[string]interface{}{"inclusionFee": nil},
+		"author_submitExtrinsic": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			submitted = true
+			return testBlockHash, nil
+		}),
+	})
+	from, _ := signer.FromSecret(signature.TestKeyringPairAlice.URI, signer.Sr25519)
+	dest := "0x" + strings.Repeat("01", 32)
+
+	tr := client.Transfer{Kind: client.TransferKeepAlive, Dest: dest, Value: uint128.From64(6e14)}
+	ext, err := c.SignProxyTransfer(from, charliePub, tr, uint128.Zero)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check, err := c.CheckProxyTransfer(ext, charliePub, tr)
+	if err != nil || !check.Transferable.Equals64(1e15) || !check.Fee.Equals64(153000000) {
+		t.Fatalf("unexpected proxy check %+v %v", check, err)
+	}
+	// alice alone could not pay this
+	if _, err := c.CheckTransfer(ext, tr); !errors.Is(err, client.ErrInsufficientBalance) {
+		t.Fatalf("expected ErrInsufficientBalance for the proxy's own funds, got %v", err)
+	}
+
+	var balanceErr *client.BalanceError
+	tr.Value = uint128.From64(2e15)
+	ext, err = c.SignProxyTransfer(from, charliePub, tr, uint128.Zero)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CheckProxyTransfer(ext, charliePub, tr); !errors.As(err, &balanceErr) ||
+		balanceErr.Account != types.HexEncodeToString(charliePub) {
+		t.Fatalf("expected charlie's balance to be insufficient, got %v", err)
+	}
+
+	// the proxy can't pay the fee
+	balances[hex.EncodeToString(alicePub)] = 1000
+	c.CheckBalances = true
+	tr.Value = uint128.From64(6e14)
+	_, err = c.AuthorProxyTransfer(from, charliePub, tr, uint128.Zero)
+	if !errors.As(err, &balanceErr) || balanceErr.Account != types.HexEncodeToString(alicePub) || submitted {
+		t.Fatalf("expected the proxy transfer to be refused for alice's fee, got %v", err)
+	}
+}
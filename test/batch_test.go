@@ -0,0 +1,134 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/DataHighway-DHX/substrate-go/client"
+	"github.com/DataHighway-DHX/substrate-go/signer"
+	"github.com/DataHighway-DHX/substrate-go/uint128"
+)
+
+func Test_NewBatchCalls(t *testing.T) {
+	// weight grows with the extrinsic length so that every transfer adds the same weight
+	c := newMockNode(t, map[string]interface{}{
+		"payment_queryInfo": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			var ext string
+			json.Unmarshal(params[0], &ext)
+			return map[string]interface{}{"weight": 1000000000 * (len(ext) - 2) / 2, "class": "Normal", "partialFee": "1"}, nil
+		}),
+		"payment_queryFeeDetails": map[string]interface{}{"inclusionFee": nil},
+	})
+	sender := bytes.Repeat([]byte{2}, 32)
+	dest := "0x" + string(bytes.Repeat([]byte("01"), 32))
+	transfers := make([]client.Transfer, 40)
+	for i := range transfers {
+		transfers[i] = client.Transfer{Dest: dest, Value: uint128.From64(uint64(i + 1))}
+	}
+	chunks, err := c.NewBatchCalls(transfers, client.BatchAll, sender, signer.Sr25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) < 2 {
+		t.Fatalf("expected the batch to be split, got %d chunks", len(chunks))
+	}
+	next := 0
+	for _, chunk := range chunks {
+		for _, i := range chunk.Items {
+			if i != next {
+				t.Fatalf("chunk items %v out of order", chunk.Items)
+			}
+			next++
+		}
+		fee, err := c.EstimateFee(chunk.Call, sender, signer.Sr25519, uint128.Zero)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// normal class max_extrinsic of the test metadata
+		if fee.Weight > 1299875000000 {
+			t.Fatalf("chunk of %d items weighs %d", len(chunk.Items), fee.Weight)
+		}
+	}
+	if next != len(transfers) {
+		t.Fatalf("%d of %d transfers batched", next, len(transfers))
+	}
+
+	_, err = c.NewBatchCalls(transfers, client.ForceBatch, sender, signer.Sr25519)
+	if !errors.Is(err, client.ErrCallNotFound) {
+		t.Fatalf("expected ErrCallNotFound, got %v", err)
+	}
+}
+
+func Test_NewBatchCallsWeightClamp(t *testing.T) {
+	// the node reports the empty batch heavier than a batch with a transfer, the transfer must not weigh less than nothing
+	c := newMockNode(t, map[string]interface{}{
+		"payment_queryInfo": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			var ext string
+			json.Unmarshal(params[0], &ext)
+			weight := 1000000000
+			if (len(ext)-2)/2 < 120 {
+				weight = 2000000000
+			}
+			return map[string]interface{}{"weight": weight, "class": "Normal", "partialFee": "1"}, nil
+		}),
+		"payment_queryFeeDetails": map[string]interface{}{"inclusionFee": nil},
+	})
+	dest := "0x" + string(bytes.Repeat([]byte("01"), 32))
+	transfers := make([]client.Transfer, 40)
+	for i := range transfers {
+		transfers[i] = client.Transfer{Dest: dest, Value: uint128.From64(uint64(i + 1))}
+	}
+	chunks, err := c.NewBatchCalls(transfers, client.BatchAll, bytes.Repeat([]byte{2}, 32), signer.Sr25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 1 || len(chunks[0].Items) != len(transfers) {
+		t.Fatalf("expected a single batch, got %d chunks", len(chunks))
+	}
+}
+
+func Test_BatchOutcome(t *testing.T) {
+	m := testMetadata(t)
+	insufficient := &client.Variant{Name: "Module", Value: map[string]interface{}{"index": big.NewInt(6), "error": client.HexBytes{2, 0, 0, 0}}}
+	res := &client.TxResult{Success: true, Events: []*client.DecodedEvent{
+		{Pallet: "Balances", Name: "Transfer"},
+		{Pallet: "Utility", Name: "ItemCompleted"},
+		{Pallet: "Utility", Name: "BatchInterrupted", Fields: map[string]interface{}{"index": big.NewInt(1), "error": insufficient}},
+	}}
+	items := outcomeString(client.BatchOutcome(m, res, []int{4, 5, 6}))
+	if items != "4:success 5:fail(Balances.InsufficientBalance) 6:skipped" {
+		t.Fatalf("unexpected outcome %s", items)
+	}
+
+	// runtimes without ItemCompleted only report the interruption
+	res.Events = res.Events[2:]
+	if items := outcomeString(client.BatchOutcome(m, res, []int{0, 1, 2})); items != "0:success 1:fail(Balances.InsufficientBalance) 2:skipped" {
+		t.Fatalf("unexpected outcome %s", items)
+	}
+	res.Events = []*client.DecodedEvent{{Pallet: "Utility", Name: "BatchCompleted"}}
+	if items := outcomeString(client.BatchOutcome(m, res, []int{0, 1})); items != "0:success 1:success" {
+		t.Fatalf("unexpected outcome %s", items)
+	}
+
+	res = &client.TxResult{Success: false, Error: "Balances.InsufficientBalance"}
+	if items := outcomeString(client.BatchOutcome(m, res, []int{0, 1})); items != "0:fail(Balances.InsufficientBalance) 1:fail(Balances.InsufficientBalance)" {
+		t.Fatalf("unexpected outcome %s", items)
+	}
+}
+
+func outcomeString(items []client.BatchItemResult) string {
+	var b bytes.Buffer
+	for i, item := range items {
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString(big.NewInt(int64(item.Transfer)).String() + ":" + item.Status)
+		if item.Error != "" {
+			b.WriteString("(" + item.Error + ")")
+		}
+	}
+	return b.String()
+}
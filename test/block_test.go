@@ -0,0 +1,99 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+func Test_BlockTimestamp(t *testing.T) {
+	// the block is from spec version 99 whose Timestamp pallet has index 42, the latest runtime has another
+	oldMeta := testMetadata(t)
+	for i := range oldMeta.AsMetadataV14.Pallets {
+		if oldMeta.AsMetadataV14.Pallets[i].Name == "Timestamp" {
+			oldMeta.AsMetadataV14.Pallets[i].Index = 42
+		}
+	}
+	oldMetaHex, err := types.EncodeToHex(oldMeta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nowKey, err := types.CreateStorageKey(oldMeta, "Timestamp", "Now")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var exts []string
+	var now interface{}
+	c := newMockNode(t, map[string]interface{}{
+		"chain_getBlock": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			return map[string]interface{}{"block": map[string]interface{}{
+				"header": map[string]interface{}{
+					"parentHash": testBlockHash, "number": "0x64", "stateRoot": testBlockHash,
+					"extrinsicsRoot": testBlockHash, "digest": map[string]interface{}{"logs": []interface{}{}},
+				},
+				"extrinsics": exts,
+			}, "justifications": nil}, nil
+		}),
+		"state_getRuntimeVersion": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			spec := 100
+			if len(params) > 0 {
+				spec = 99
+			}
+			return map[string]interface{}{
+				"specName": "substrate", "implName": "substrate", "authoringVersion": 1,
+				"specVersion": spec, "implVersion": 1, "transactionVersion": 1, "apis": []interface{}{},
+			}, nil
+		}),
+		"state_getMetadata": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			if len(params) > 0 {
+				return oldMetaHex, nil
+			}
+			return types.MetadataV14Data, nil
+		}),
+		"state_getStorage": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			var key string
+			json.Unmarshal(params[0], &key)
+			switch key {
+			case eventsKey:
+				return "0x00", nil
+			case nowKey.Hex():
+				return now, nil
+			}
+			return nil, nil
+		}),
+	})
+
+	// Timestamp.set inherent, found with the call index of the block's runtime
+	args, _ := types.Encode(types.NewUCompactFromUInt(1700000000123))
+	inherent, _ := types.EncodeToHex(types.NewExtrinsic(types.Call{CallIndex: types.CallIndex{SectionIndex: 42}, Args: args}))
+	exts, now = []string{inherent}, nil
+	block, err := c.GetBlockByNumber(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.Timestamp == nil || *block.Timestamp != 1700000000 {
+		t.Fatalf("expected the timestamp of the inherent, got %v", block.Timestamp)
+	}
+
+	// no inherent, Timestamp.Now is read at the block
+	exts, now = []string{}, "0x502be6cf8b010000"
+	block, err = c.GetBlockByNumber(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.Timestamp == nil || *block.Timestamp != 1700000050 {
+		t.Fatalf("expected the timestamp from storage, got %v", block.Timestamp)
+	}
+
+	// neither, e.g. the genesis block
+	exts, now = []string{}, nil
+	block, err = c.GetBlockByNumber(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.Timestamp != nil {
+		t.Fatalf("expected no timestamp, got %d", *block.Timestamp)
+	}
+}
@@ -0,0 +1,151 @@
+package test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/DataHighway-DHX/substrate-go/client"
+	"github.com/DataHighway-DHX/substrate-go/signer"
+	"github.com/DataHighway-DHX/substrate-go/uint128"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"golang.org/x/crypto/blake2b"
+)
+
+func mustCall(t *testing.T, meta *types.Metadata, name string, args ...interface{}) types.Call {
+	ca, err := client.NewCall(meta, name, args...)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	return ca
+}
+
+func derivativeAccount(who []byte, index uint16) []byte {
+	h := blake2b.Sum256(append(append([]byte("modlpy/utilisuba"), who...), byte(index), byte(index>>8)))
+	return h[:]
+}
+
+func Test_CallTransfers(t *testing.T) {
+	meta := testMetadata(t)
+	dest := bytes.Repeat([]byte{1}, 32)
+	transfer := func(name string, value uint64) types.Call {
+		return mustCall(t, meta, name, dest, types.NewUCompactFromUInt(value))
+	}
+	// charlie's proxy alice approves as a 2 of 3 multisig, which transfers from its derivative account 3
+	derivative := mustCall(t, meta, "Utility.as_derivative", uint16(3), transfer("Balances.transfer", 3))
+	asMulti := mustCall(t, meta, "Multisig.as_multi", uint16(2), []interface{}{bobPub, alicePub}, nil, derivative, false, uint64(0))
+	batch := mustCall(t, meta, "Utility.batch_all", []interface{}{
+		transfer("Balances.transfer", 1),
+		mustCall(t, meta, "Proxy.proxy", charliePub, nil, transfer("Balances.transfer_keep_alive", 2)),
+		mustCall(t, meta, "Proxy.proxy", charliePub, nil, asMulti),
+		mustCall(t, meta, "Balances.force_transfer", bobPub, dest, types.NewUCompactFromUInt(4)),
+	})
+
+	transfers, err := client.CallTransfers(meta, batch, alicePub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	multisig := client.MultisigAccountId([][]byte{charliePub, bobPub, alicePub}, 2)
+	want := []struct {
+		path   string
+		origin []byte
+	}{
+		{"batch_all[0].transfer", alicePub},
+		{"batch_all[1].proxy.transfer_keep_alive", charliePub},
+		{"batch_all[2].proxy.as_multi.as_derivative.transfer", derivativeAccount(multisig, 3)},
+		{"batch_all[3].force_transfer", bobPub},
+	}
+	if len(transfers) != len(want) {
+		t.Fatalf("got %d transfers, want %d", len(transfers), len(want))
+	}
+	for i, w := range want {
+		tr := transfers[i]
+		if tr.Path != w.path || !bytes.Equal(tr.Origin, w.origin) || tr.Amount.Int64() != int64(i+1) {
+			t.Fatalf("transfer %d: got %s from %x amount %s, want %s from %x", i, tr.Path, tr.Origin, tr.Amount, w.path, w.origin)
+		}
+	}
+
+	// a multisig approved by an unknown origin has no known origin
+	transfers, err = client.CallTransfers(meta, asMulti, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transfers) != 1 || transfers[0].Origin != nil {
+		t.Fatalf("unexpected transfers %+v", transfers)
+	}
+
+	if _, err := client.CallTransfers(meta, types.Call{CallIndex: types.CallIndex{SectionIndex: 0xff}}, alicePub); err == nil {
+		t.Fatal("expected an error for an unknown pallet")
+	}
+}
+
+func Test_BlockTransferCallPath(t *testing.T) {
+	// ApplyExtrinsic(index), Balances.Transfer(from, to, amount), no topics
+	transferEvent := func(index byte, from []byte, amount uint64) string {
+		var value [16]byte
+		uint128.From64(amount).PutBytes(value[:])
+		return "00" + hex.EncodeToString([]byte{index, 0, 0, 0}) + "0602" + hex.EncodeToString(from) +
+			strings.Repeat("01", 32) + hex.EncodeToString(value[:]) + "00"
+	}
+	var exts []string
+	c := newMockNode(t, map[string]interface{}{
+		"chain_getBlock": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			return map[string]interface{}{"block": map[string]interface{}{
+				"header": map[string]interface{}{
+					"parentHash": testBlockHash, "number": "0x64", "stateRoot": testBlockHash,
+					"extrinsicsRoot": testBlockHash, "digest": map[string]interface{}{"logs": []interface{}{}},
+				},
+				"extrinsics": exts,
+			}, "justifications": nil}, nil
+		}),
+		"state_getStorage": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			var key string
+			json.Unmarshal(params[0], &key)
+			if key == eventsKey {
+				return "0x08" + transferEvent(0, charliePub, 5) + transferEvent(1, alicePub, 7), nil
+			}
+			return nil, nil
+		}),
+		"payment_queryInfo": map[string]interface{}{"partialFee": "1000"},
+	})
+	c.Nonces = client.NewNonceManager(func([]byte) (uint64, error) { return 0, nil })
+	from, _ := signer.FromSecret(signature.TestKeyringPairAlice.URI, signer.Sr25519)
+
+	transfer := mustCall(t, c.Meta, "Balances.transfer_keep_alive", bytes.Repeat([]byte{1}, 32), types.NewUCompactFromUInt(5))
+	proxied, err := c.SignCall(from, mustCall(t, c.Meta, "Proxy.proxy", charliePub, nil, transfer), uint128.Zero)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a call of a pallet the metadata does not know
+	unknown, err := c.SignCall(from, types.Call{CallIndex: types.CallIndex{SectionIndex: 0xff}}, uint128.Zero)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ext := range []types.Extrinsic{proxied, unknown} {
+		h, _ := types.EncodeToHex(ext)
+		exts = append(exts, h)
+	}
+
+	block, err := c.GetBlockByNumber(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(block.Extrinsic) != 2 {
+		t.Fatalf("got %d transfers", len(block.Extrinsic))
+	}
+	proxy := block.Extrinsic[0]
+	if proxy.CallPath != "proxy.transfer_keep_alive" || proxy.DestType != "Id" || proxy.DecodeError != "" ||
+		proxy.FromPublicKey != types.HexEncodeToString(charliePub) {
+		t.Fatalf("unexpected proxied transfer %+v", proxy)
+	}
+	undecoded := block.Extrinsic[1]
+	if undecoded.DecodeError == "" || undecoded.CallPath != "" || undecoded.Amount != "7" {
+		t.Fatalf("expected a flagged transfer, got %+v", undecoded)
+	}
+	if block.Timestamp != nil {
+		t.Fatalf("unexpected timestamp %d", *block.Timestamp)
+	}
+}
@@ -0,0 +1,105 @@
+package test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/DataHighway-DHX/substrate-go/client"
+	"github.com/DataHighway-DHX/substrate-go/signer"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+func Test_SignerAccounts(t *testing.T) {
+	for _, tt := range []struct {
+		crypto  signer.CryptoType
+		public  string
+		account string
+	}{
+		{signer.Ed25519, "0x88dc3417d5058ec4b4503e0c12ea1a0a89be200fe98922423d4334014fa6b0ee", "0x88dc3417d5058ec4b4503e0c12ea1a0a89be200fe98922423d4334014fa6b0ee"},
+		{signer.Ecdsa, "0x020a1091341fe5664bfa1782d5e04779689068c916b04cb365ec3153755684d9a1", "0x01e552298e47454041ea31273b4b630c64c104e4514aa3643490b8aaca9cf8ed"},
+	} {
+		kr, err := signer.FromSecret("//Alice", tt.crypto)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pub := types.HexEncodeToString(kr.PublicKey()); pub != tt.public {
+			t.Errorf("%s public key %s, want %s", tt.crypto, pub, tt.public)
+		}
+		if acc := types.HexEncodeToString(signer.AccountId(kr)); acc != tt.account {
+			t.Errorf("%s account id %s, want %s", tt.crypto, acc, tt.account)
+		}
+	}
+}
+
+func Test_SignWithCryptoTypes(t *testing.T) {
+	for _, crypto := range []signer.CryptoType{signer.Ed25519, signer.Sr25519, signer.Ecdsa} {
+		kr, secret, err := signer.Generate(crypto)
+		if err != nil {
+			t.Fatal(err)
+		}
+		imported, err := signer.FromSecret(secret, crypto)
+		if err != nil || !bytes.Equal(imported.PublicKey(), kr.PublicKey()) {
+			t.Fatalf("%s secret does not restore the key pair: %v", crypto, err)
+		}
+
+		unsigned := &client.UnsignedTx{
+			Signer:             types.HexEncodeToString(signer.AccountId(kr)),
+			Crypto:             crypto.String(),
+			Call:               "0x0600ff8eaf04151687736326c9fea17e25fc5287613693c912909cb226aa4794f26a4804",
+			Era:                "0x00",
+			Tip:                "0",
+			SpecVersion:        100,
+			TransactionVersion: 1,
+			GenesisHash:        testBlockHash,
+			BlockHash:          testBlockHash,
+		}
+		signed, err := client.SignUnsignedTx(unsigned, kr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ext, err := signed.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ms := ext.Signature.Signature
+		var sig []byte
+		switch {
+		case crypto == signer.Ed25519 && ms.IsEd25519:
+			sig = ms.AsEd25519[:]
+		case crypto == signer.Sr25519 && ms.IsSr25519:
+			sig = ms.AsSr25519[:]
+		case crypto == signer.Ecdsa && ms.IsEcdsa:
+			sig = ms.AsEcdsa[:]
+		default:
+			t.Fatalf("%s extrinsic has signature %+v", crypto, ms)
+		}
+		payload, _ := unsigned.SigningPayload()
+		if !kr.Verify(payload, sig) {
+			t.Fatalf("%s signature does not verify", crypto)
+		}
+		if !bytes.Equal(ext.Signature.Signer.AsID[:], signer.AccountId(kr)) {
+			t.Fatalf("%s extrinsic signed by %x", crypto, ext.Signature.Signer.AsID)
+		}
+	}
+}
+
+func Test_InvalidEcdsaSeed(t *testing.T) {
+	// zero, the secp256k1 curve order N and the largest 32 byte value
+	for _, seed := range []string{
+		"0x" + strings.Repeat("00", 32),
+		"0xfffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141",
+		"0x" + strings.Repeat("ff", 32),
+	} {
+		if _, err := signer.FromSeed(types.MustHexDecodeString(seed), signer.Ecdsa); err == nil {
+			t.Errorf("expected an error for ecdsa seed %s", seed)
+		}
+		if _, err := signer.FromSecret(seed, signer.Ecdsa); err == nil {
+			t.Errorf("expected an error for ecdsa secret %s", seed)
+		}
+	}
+	// N-1 is the largest valid secret
+	if _, err := signer.FromSeed(types.MustHexDecodeString("0xfffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364140"), signer.Ecdsa); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,67 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/DataHighway-DHX/substrate-go/client"
+	"github.com/DataHighway-DHX/substrate-go/signer"
+	"github.com/DataHighway-DHX/substrate-go/uint128"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+func Test_DecodeApplyExtrinsicResult(t *testing.T) {
+	meta := testMetadata(t)
+	cases := []struct {
+		hex             string
+		validity, error string
+	}{
+		{"0x0000", "", ""},
+		{"0x0001030602", "", "Balances.InsufficientBalance"},
+		{"0x00010700", "", "Token.NoFunds"},
+		{"0x00010202", "", "BadOrigin"},
+		{"0x010003", "Invalid.Stale", ""},
+		{"0x01000705", "Invalid.Custom(5)", ""},
+		{"0x010101", "Unknown.NoUnsignedValidator", ""},
+	}
+	for _, c := range cases {
+		b, _ := types.HexDecodeString(c.hex)
+		res, err := client.DecodeApplyExtrinsicResult(meta, b)
+		if err != nil {
+			t.Fatalf("%s: %v", c.hex, err)
+		}
+		if res.Validity != c.validity || res.DispatchError != c.error || res.Success != (c.validity == "" && c.error == "") {
+			t.Fatalf("%s: unexpected result %+v", c.hex, res)
+		}
+	}
+}
+
+func Test_DryRunTransfer(t *testing.T) {
+	submitted := false
+	c := newMockNode(t, map[string]interface{}{
+		"system_dryRun": "0x0001030602",
+		"author_submitExtrinsic": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			submitted = true
+			return testBlockHash, nil
+		}),
+	})
+	c.DryRunTransfers = true
+	c.Nonces = client.NewNonceManager(func([]byte) (uint64, error) { return 3, nil })
+
+	alice := signature.TestKeyringPairAlice
+	from, _ := signer.FromSecret(alice.URI, signer.Sr25519)
+	_, err := c.AuthorTransfer(from, client.Transfer{Dest: "0x" + strings.Repeat("01", 32), Value: uint128.From64(1)}, uint128.Zero)
+	var dryRunErr *client.DryRunError
+	if !errors.As(err, &dryRunErr) || dryRunErr.DispatchError != "Balances.InsufficientBalance" {
+		t.Fatalf("expected a dry run error, got %v", err)
+	}
+	if submitted {
+		t.Fatal("failing transfer was submitted")
+	}
+	if n, _ := c.Nonces.Next(alice.PublicKey); n != 3 {
+		t.Fatalf("nonce of the refused transfer was not released, got %d", n)
+	}
+}
@@ -0,0 +1,103 @@
+package test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/DataHighway-DHX/substrate-go/client"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+func testMetadata(t *testing.T) *types.Metadata {
+	var meta types.Metadata
+	if err := types.DecodeFromHex(types.MetadataV14Data, &meta); err != nil {
+		t.Fatal(err)
+	}
+	return &meta
+}
+
+func Test_NewCall(t *testing.T) {
+	meta := testMetadata(t)
+	dest := types.NewMultiAddressFromAccountID(bytes.Repeat([]byte{1}, 32))
+
+	ca, err := client.NewCall(meta, "Balances.transfer", dest, types.NewUCompactFromUInt(1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ca.CallIndex != (types.CallIndex{SectionIndex: 6, MethodIndex: 0}) {
+		t.Fatalf("unexpected call index %+v", ca.CallIndex)
+	}
+	want := append(append([]byte{0}, bytes.Repeat([]byte{1}, 32)...), 0xa1, 0x0f)
+	if !bytes.Equal(ca.Args, want) {
+		t.Fatalf("unexpected args %x", ca.Args)
+	}
+
+	named, err := client.NewCallWithArgs(meta, "Balances.transfer", map[string]interface{}{
+		"dest":  "0x" + strings.Repeat("01", 32),
+		"value": 1000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if named.CallIndex != ca.CallIndex || !bytes.Equal(named.Args, ca.Args) {
+		t.Fatalf("named args encode differently: %x", named.Args)
+	}
+
+	batch, err := client.NewCallFromJSON(meta, "Utility.batch", []byte(`{"calls": [
+		{"call": "Balances.transfer", "args": {"dest": {"Id": "0x`+strings.Repeat("01", 32)+`"}, "value": "1000"}},
+		{"call": "System.remark", "args": {"remark": "hello"}}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if batch.CallIndex.SectionIndex != 1 || batch.Args[0] != 2<<2 || !bytes.Equal(batch.Args[3:3+len(want)], want) {
+		t.Fatalf("unexpected batch %+v", batch)
+	}
+}
+
+func Test_NewCallErrors(t *testing.T) {
+	meta := testMetadata(t)
+	dest := "0x" + strings.Repeat("01", 32)
+	cases := []struct {
+		call string
+		args string
+		want string
+	}{
+		{"Balances.transfer", `[1]`, "takes 2 args"},
+		{"Balances.transfer", `{"dest": "` + dest + `", "value": -1}`, "value"},
+		{"Balances.transfer", `{"dest": "0x01", "value": 1}`, "dest"},
+		{"Balances.transfer", `{"dest": "` + dest + `", "value": 1, "memo": 1}`, "memo"},
+		{"Balances.transfer", `{"dest": "` + dest + `"}`, "value: missing"},
+		{"Balances.nope", `{}`, client.ErrCallNotFound.Error()},
+	}
+	for _, c := range cases {
+		_, err := client.NewCallFromJSON(meta, c.call, []byte(c.args))
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Fatalf("%s %s: got error %v, want %q", c.call, c.args, err, c.want)
+		}
+	}
+}
+
+func Test_NewCallAccountIdVariant(t *testing.T) {
+	meta := testMetadata(t)
+	dest := "0x" + strings.Repeat("01", 32)
+	// a bare account id stands for MultiAddress::Id
+	if _, err := client.NewCallFromJSON(meta, "Balances.transfer", []byte(`{"dest": "`+dest+`", "value": 1}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	// but not for another enum that happens to have an Id variant
+	for _, ty := range meta.AsMetadataV14.EfficientLookup {
+		if n := len(ty.Path); n > 0 && ty.Path[n-1] == "MultiAddress" {
+			ty.Path[n-1] = "Location"
+		}
+	}
+	_, err := client.NewCallFromJSON(meta, "Balances.transfer", []byte(`{"dest": "`+dest+`", "value": 1}`))
+	if err == nil || !strings.Contains(err.Error(), "expected a variant") {
+		t.Fatalf("expected a variant error, got %v", err)
+	}
+	if _, err := client.NewCallFromJSON(meta, "Balances.transfer", []byte(`{"dest": {"Id": "`+dest+`"}, "value": 1}`)); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,36 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/DataHighway-DHX/substrate-go/client"
+)
+
+func Test_NewMortalEra(t *testing.T) {
+	cases := []struct {
+		period, current, birth uint64
+		first, second          byte
+	}{
+		{64, 42, 42, 0xa5, 0x02},
+		{32768, 20000, 20000, 78, 156},
+		{64, 1000, 1000, 0x85, 0x02},
+	}
+	for _, c := range cases {
+		era, birth, err := client.NewMortalEra(c.period, c.current)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !era.IsMortalEra || era.AsMortalEra.First != c.first || era.AsMortalEra.Second != c.second {
+			t.Fatalf("period %d current %d: got %+v", c.period, c.current, era.AsMortalEra)
+		}
+		if birth != c.birth {
+			t.Fatalf("period %d current %d: got birth %d", c.period, c.current, birth)
+		}
+	}
+
+	for _, period := range []uint64{0, 2, 63, 1 << 17} {
+		if _, _, err := client.NewMortalEra(period, 100); err == nil {
+			t.Fatalf("period %d: expected error", period)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/DataHighway-DHX/substrate-go/client"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+func Test_DecodeEvents(t *testing.T) {
+	meta := testMetadata(t)
+	raw, err := types.HexDecodeString("0x08" +
+		// ApplyExtrinsic(1), System.ExtrinsicFailed(Module {index: 6, error: 2}, DispatchInfo)
+		"0001000000" + "0001" + "030602" + "1027000000000000" + "00" + "00" + "00" +
+		// Finalization, Balances.Deposit
+		"01" + "0607" + "d43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d" + "e8030000000000000000000000000000" + "00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, err := client.DecodeEvents(meta, raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("got %d events", len(events))
+	}
+	failed := events[0]
+	if !failed.Is("System", "ExtrinsicFailed") || failed.Phase != "ApplyExtrinsic" || failed.ExtrinsicIndex != 1 {
+		t.Fatalf("unexpected event %+v", failed)
+	}
+	if err, ok := failed.Field("dispatch_error", 0); !ok || err.(*client.Variant).Name != "Module" {
+		t.Fatalf("unexpected dispatch error %v", err)
+	}
+	deposit := events[1]
+	if !deposit.Is("Balances", "Deposit") || deposit.Phase != "Finalization" || deposit.ExtrinsicIndex != -1 {
+		t.Fatalf("unexpected event %+v", deposit)
+	}
+	if amount, _ := deposit.Field("amount", 1); amount.(interface{ String() string }).String() != "1000" {
+		t.Fatalf("unexpected amount %v", amount)
+	}
+}
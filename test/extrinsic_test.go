@@ -0,0 +1,107 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DataHighway-DHX/substrate-go/client"
+	"github.com/DataHighway-DHX/substrate-go/signer"
+	"github.com/DataHighway-DHX/substrate-go/ss58"
+	"github.com/DataHighway-DHX/substrate-go/uint128"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"golang.org/x/crypto/blake2b"
+)
+
+func Test_DecodeExtrinsic(t *testing.T) {
+	c := newMockNode(t, nil)
+	c.Nonces = client.NewNonceManager(func([]byte) (uint64, error) { return 9, nil })
+	alice := signature.TestKeyringPairAlice
+	from, _ := signer.FromSecret(alice.URI, signer.Sr25519)
+	tr := client.Transfer{Kind: client.TransferKeepAlive, Dest: "0x" + strings.Repeat("01", 32), Value: uint128.From64(12345)}
+	ext, err := c.SignTransfer(from, tr, uint128.From64(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	extHex, _ := types.EncodeToHex(ext)
+
+	d, err := client.DecodeExtrinsic(extHex, c.Meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Signed || d.Version != 4 || d.Signer != alice.Address || d.SignerPublicKey != types.HexEncodeToString(alice.PublicKey) ||
+		d.SignatureType != "Sr25519" || d.Nonce != 9 || d.Tip != "7" || d.EraPeriod != 64 || d.EraPhase != 100%64 {
+		t.Fatalf("unexpected decoded extrinsic %+v", d)
+	}
+	// txid and length as a block reports them
+	txid := blake2b.Sum256(types.MustHexDecodeString(extHex))
+	if d.Txid != types.HexEncodeToString(txid[:]) || d.Length+2 != (len(extHex)-2)/2 {
+		t.Fatalf("unexpected txid %s or length %d", d.Txid, d.Length)
+	}
+	value, _ := d.Call.Arg("value")
+	if d.Call.Pallet != "Balances" || d.Call.Call != "transfer_keep_alive" || fmt.Sprint(value) != "12345" {
+		t.Fatalf("unexpected call %+v", d.Call)
+	}
+
+	data, err := d.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out["signer"] != alice.Address || out["signature_type"] != "Sr25519" || out["call"].(map[string]interface{})["call"] != "transfer_keep_alive" {
+		t.Fatalf("unexpected json %s", data)
+	}
+
+	unsigned, _ := types.EncodeToHex(types.NewExtrinsic(ext.Method))
+	d, err = client.DecodeExtrinsic(unsigned, c.Meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Signed || d.Signer != "" || d.Call.Call != "transfer_keep_alive" {
+		t.Fatalf("unexpected unsigned extrinsic %+v", d)
+	}
+
+	if _, err := client.DecodeExtrinsic("0x1234", c.Meta); err == nil {
+		t.Fatal("expected an error for invalid extrinsic hex")
+	}
+}
+
+func Test_DecodeExtrinsicSignerPrefix(t *testing.T) {
+	c := newMockNode(t, nil)
+	c.Nonces = client.NewNonceManager(func([]byte) (uint64, error) { return 0, nil })
+	alice := signature.TestKeyringPairAlice
+	from, _ := signer.FromSecret(alice.URI, signer.Sr25519)
+	ext, err := c.SignTransfer(from, client.Transfer{Dest: "0x" + strings.Repeat("01", 32), Value: uint128.From64(1)}, uint128.Zero)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// an Address32 signer on a runtime whose SS58 prefix does not fit a byte
+	ext.Signature.Signer = types.MultiAddress{IsAddress32: true}
+	copy(ext.Signature.Signer.AsAddress32[:], alice.PublicKey)
+	extHex, _ := types.EncodeToHex(ext)
+	meta := testMetadata(t)
+	for i, mod := range meta.AsMetadataV14.Pallets {
+		if mod.Name != "System" {
+			continue
+		}
+		for j, cons := range mod.Constants {
+			if cons.Name == "SS58Prefix" {
+				meta.AsMetadataV14.Pallets[i].Constants[j].Value = types.Bytes{0x04, 0x05}
+			}
+		}
+	}
+
+	d, err := client.DecodeExtrinsic(extHex, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := ss58.EncodeWithNetwork(alice.PublicKey, 1284)
+	if d.Signer != want {
+		t.Fatalf("signer %s, want %s", d.Signer, want)
+	}
+}
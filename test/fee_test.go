@@ -0,0 +1,57 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/DataHighway-DHX/substrate-go/client"
+	"github.com/DataHighway-DHX/substrate-go/signer"
+	"github.com/DataHighway-DHX/substrate-go/uint128"
+)
+
+func Test_EstimateFee(t *testing.T) {
+	var length int
+	c := newMockNode(t, map[string]interface{}{
+		"payment_queryInfo": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			var ext string
+			json.Unmarshal(params[0], &ext)
+			length = (len(ext) - 2) / 2
+			return map[string]interface{}{
+				"weight": map[string]interface{}{"refTime": 195000000, "proofSize": 0},
+				"class":  "Normal", "partialFee": "153000000",
+			}, nil
+		}),
+		"payment_queryFeeDetails": map[string]interface{}{
+			"inclusionFee": map[string]interface{}{"baseFee": "0x5f5e100", "lenFee": "0x2faf080", "adjustedWeightFee": 3000000},
+		},
+	})
+	dest := "0x" + string(bytes.Repeat([]byte("01"), 32))
+	transfer := client.Transfer{Kind: client.TransferKeepAlive, Dest: dest, Value: uint128.From64(1000)}
+	fee, err := c.EstimateTransferFee(transfer, bytes.Repeat([]byte{2}, 32), signer.Sr25519, uint128.From64(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fee.Weight != 195000000 || fee.Class != "normal" || fee.PartialFee.Raw != "153000000" {
+		t.Fatalf("unexpected fee %+v", fee)
+	}
+	if fee.BaseFee.Raw != "100000000" || fee.LenFee.Raw != "50000000" || fee.AdjustedWeightFee.Raw != "3000000" {
+		t.Fatalf("unexpected fee details %+v %+v %+v", fee.BaseFee, fee.LenFee, fee.AdjustedWeightFee)
+	}
+	if fee.Total.Raw != "153000005" || fee.Total.Formatted != "0.000153000005" {
+		t.Fatalf("unexpected total %+v", fee.Total)
+	}
+	// compact length prefix plus the signed extrinsic
+	if fee.Length+2 != length {
+		t.Fatalf("estimated length %d, submitted %d bytes", fee.Length, length)
+	}
+
+	// an ecdsa signature is one byte longer
+	ecdsa, err := c.EstimateTransferFee(transfer, bytes.Repeat([]byte{2}, 32), signer.Ecdsa, uint128.From64(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ecdsa.Length != fee.Length+1 || ecdsa.Length+2 != length {
+		t.Fatalf("ecdsa estimated length %d, sr25519 %d, submitted %d bytes", ecdsa.Length, fee.Length, length)
+	}
+}
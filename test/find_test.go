@@ -0,0 +1,168 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DataHighway-DHX/substrate-go/client"
+	"github.com/DataHighway-DHX/substrate-go/signer"
+	"github.com/DataHighway-DHX/substrate-go/uint128"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"golang.org/x/crypto/blake2b"
+)
+
+func Test_FindExtrinsic(t *testing.T) {
+	blocks := make(map[int64][]string)
+	var blockReads int
+	var metadataAt []string
+	header := func(height int64) map[string]interface{} {
+		return map[string]interface{}{
+			"parentHash": testBlockHash, "number": fmt.Sprintf("0x%x", height), "stateRoot": testBlockHash,
+			"extrinsicsRoot": testBlockHash, "digest": map[string]interface{}{"logs": []interface{}{}},
+		}
+	}
+	c := newMockNode(t, map[string]interface{}{
+		// block 100 is the best block, 99 the finalized one
+		"chain_getFinalizedHead": fmt.Sprintf("0x%064x", 99),
+		"chain_getHeader": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			height := int64(100)
+			if len(params) > 0 {
+				var hash string
+				json.Unmarshal(params[0], &hash)
+				fmt.Sscanf(hash, "0x%x", &height)
+			}
+			return header(height), nil
+		}),
+		"chain_getBlockHash": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			var height int64
+			json.Unmarshal(params[0], &height)
+			return fmt.Sprintf("0x%064x", height), nil
+		}),
+		"chain_getBlock": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			blockReads++
+			var hash string
+			json.Unmarshal(params[0], &hash)
+			var height int64
+			fmt.Sscanf(hash, "0x%x", &height)
+			exts := blocks[height]
+			if exts == nil {
+				exts = []string{}
+			}
+			return map[string]interface{}{"block": map[string]interface{}{"header": header(height), "extrinsics": exts}, "justifications": nil}, nil
+		}),
+		// blocks are from spec version 99, an older runtime than the latest
+		"state_getRuntimeVersion": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			spec := 100
+			if len(params) > 0 {
+				spec = 99
+			}
+			return map[string]interface{}{
+				"specName": "substrate", "implName": "substrate", "authoringVersion": 1,
+				"specVersion": spec, "implVersion": 1, "transactionVersion": 1, "apis": []interface{}{},
+			}, nil
+		}),
+		"state_getMetadata": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			if len(params) > 0 {
+				var hash string
+				json.Unmarshal(params[0], &hash)
+				metadataAt = append(metadataAt, hash)
+			}
+			return types.MetadataV14Data, nil
+		}),
+		"state_getStorage": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			// ApplyExtrinsic(1), System.ExtrinsicFailed(Module {index: 6, error: 2}, DispatchInfo)
+			return "0x04" + "0001000000" + "0001" + "030602" + "1027000000000000" + "00" + "00" + "00", nil
+		}),
+		"payment_queryInfo": map[string]interface{}{"partialFee": "1000"},
+	})
+	c.Nonces = client.NewNonceManager(func([]byte) (uint64, error) { return 0, nil })
+	from, _ := signer.FromSecret(signature.TestKeyringPairAlice.URI, signer.Sr25519)
+
+	var txHash types.Hash
+	for height := int64(98); height <= 100; height++ {
+		for i := 0; i < 2; i++ {
+			ext, err := c.SignTransfer(from, client.Transfer{Dest: "0x" + strings.Repeat("01", 32), Value: uint128.From64(uint64(height))}, uint128.Zero)
+			if err != nil {
+				t.Fatal(err)
+			}
+			h, _ := types.EncodeToHex(ext)
+			blocks[height] = append(blocks[height], h)
+			if height == 99 && i == 1 {
+				txHash = blake2b.Sum256(types.MustHexDecodeString(h))
+			}
+		}
+	}
+
+	if _, err := c.FindExtrinsic(context.Background(), txHash, 1); !errors.Is(err, client.ErrExtrinsicNotFound) {
+		t.Fatalf("expected ErrExtrinsicNotFound outside the search window, got %v", err)
+	}
+	c.TxIndex = client.NewTxIndex(10)
+	loc, err := c.FindExtrinsic(context.Background(), txHash, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc.Height != 99 || loc.ExtrinsicIndex != 1 || loc.Status != "fail" || loc.Fee != "1000" || loc.Txid != txHash.Hex() {
+		t.Fatalf("unexpected location %+v", loc)
+	}
+	if len(metadataAt) == 0 || metadataAt[0] != fmt.Sprintf("0x%064x", 99) {
+		t.Fatalf("events were not decoded with the metadata of the block, metadata read at %v", metadataAt)
+	}
+
+	// only the scanned blocks that are finalized are indexed, a second lookup reads only the block of the extrinsic
+	if c.TxIndex.Len() != 2 {
+		t.Fatalf("expected the 2 extrinsics of the finalized block indexed, got %d", c.TxIndex.Len())
+	}
+	before := blockReads
+	loc, err = c.FindExtrinsic(context.Background(), txHash, 3)
+	if err != nil || loc.Height != 99 || blockReads != before+1 {
+		t.Fatalf("expected an indexed lookup, got %+v %v after %d block reads", loc, err, blockReads-before)
+	}
+}
+
+func Test_FindExtrinsicProxyEvent(t *testing.T) {
+	var block []string
+	c := newMockNode(t, map[string]interface{}{
+		"chain_getBlock": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			return map[string]interface{}{"block": map[string]interface{}{
+				"header": map[string]interface{}{
+					"parentHash": testBlockHash, "number": "0x64", "stateRoot": testBlockHash,
+					"extrinsicsRoot": testBlockHash, "digest": map[string]interface{}{"logs": []interface{}{}},
+				},
+				"extrinsics": block,
+			}, "justifications": nil}, nil
+		}),
+		"state_getStorage": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			return "0x08" +
+				// ApplyExtrinsic(0), Proxy.ProxyAdded(alice, bob, NonTransfer, 10), no field of types.EventRecords
+				"0000000000" + "2003" + types.HexEncodeToString(alicePub)[2:] + types.HexEncodeToString(bobPub)[2:] + "01" + "0a000000" + "00" +
+				// ApplyExtrinsic(0), System.ExtrinsicSuccess(DispatchInfo)
+				"0000000000" + "0000" + "1027000000000000" + "00" + "00" + "00", nil
+		}),
+		"payment_queryInfo": map[string]interface{}{"partialFee": "1000"},
+	})
+	c.Nonces = client.NewNonceManager(func([]byte) (uint64, error) { return 0, nil })
+	from, _ := signer.FromSecret(signature.TestKeyringPairAlice.URI, signer.Sr25519)
+	add, err := c.NewAddProxy(bobPub, "NonTransfer", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ext, err := c.SignCall(from, add, uint128.Zero)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, _ := types.EncodeToHex(ext)
+	block = []string{h}
+
+	loc, err := c.FindExtrinsic(context.Background(), blake2b.Sum256(types.MustHexDecodeString(h)), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc.Height != 100 || loc.ExtrinsicIndex != 0 || loc.Status != "success" {
+		t.Fatalf("unexpected location %+v", loc)
+	}
+}
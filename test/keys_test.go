@@ -0,0 +1,81 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/DataHighway-DHX/substrate-go/keys"
+	"github.com/DataHighway-DHX/substrate-go/signer"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+const devPhrase = "bottom drive obey lake curtain smoke basket hold race lonely fit walk"
+
+func Test_Mnemonic(t *testing.T) {
+	for _, words := range []int{12, 24} {
+		m, err := keys.GenerateMnemonic(words)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(strings.Fields(m)) != words || keys.ValidateMnemonic(m) != nil {
+			t.Fatalf("invalid generated mnemonic %q", m)
+		}
+	}
+	bad := strings.Replace(devPhrase, "walk", "abandon", 1)
+	if err := keys.ValidateMnemonic(bad); !errors.Is(err, keys.ErrInvalidMnemonic) {
+		t.Fatalf("expected ErrInvalidMnemonic, got %v", err)
+	}
+	secret, err := keys.MiniSecret(devPhrase, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := types.HexEncodeToString(secret[:]); s != "0xfac7959dbfe72f052e5a0c3c8d6530f202b02fd8f9f5ca3580ec8deb7797479e" {
+		t.Fatalf("unexpected mini secret %s", s)
+	}
+}
+
+func Test_DerivePath(t *testing.T) {
+	alice, err := keys.Derive(devPhrase, "", "//Alice", signer.Sr25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pub := types.HexEncodeToString(alice.PublicKey()); pub != "0xd43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d" {
+		t.Fatalf("unexpected //Alice public key %s", pub)
+	}
+
+	junctions, err := keys.ParsePath("//hot//0/deposit")
+	if err != nil || len(junctions) != 3 || !junctions[0].Hard || !junctions[1].Hard || junctions[2].Hard {
+		t.Fatalf("unexpected junctions %+v %v", junctions, err)
+	}
+	if junctions[1].ChainCode != [32]byte{} {
+		t.Fatalf("junction 0 has chain code %x", junctions[1].ChainCode)
+	}
+	if _, err := keys.ParsePath("hot"); err == nil {
+		t.Fatal("expected error for a path without leading slash")
+	}
+	phrase, path, password, err := keys.ParseURI(devPhrase + "//hot/1///secret")
+	if err != nil || phrase != devPhrase || path != "//hot/1" || password != "secret" {
+		t.Fatalf("unexpected URI parts %q %q %q %v", phrase, path, password, err)
+	}
+
+	hot, err := keys.Derive(devPhrase, "", "//hot", signer.Sr25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deposit, err := keys.Derive(devPhrase, "", "//hot/deposit/42", signer.Sr25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	watched, err := keys.DerivePublic(hot.PublicKey(), "/deposit/42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(watched, deposit.PublicKey()) {
+		t.Fatalf("public derivation %x, secret derivation %x", watched, deposit.PublicKey())
+	}
+	if _, err := keys.DerivePublic(hot.PublicKey(), "//deposit"); !errors.Is(err, keys.ErrHardJunction) {
+		t.Fatalf("expected ErrHardJunction, got %v", err)
+	}
+}
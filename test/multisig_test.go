@@ -0,0 +1,93 @@
+package test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/DataHighway-DHX/substrate-go/client"
+	"github.com/DataHighway-DHX/substrate-go/ss58"
+	"github.com/DataHighway-DHX/substrate-go/uint128"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+var (
+	alicePub, _   = types.HexDecodeString("0xd43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d")
+	bobPub, _     = types.HexDecodeString("0x8eaf04151687736326c9fea17e25fc5287613693c912909cb226aa4794f26a48")
+	charliePub, _ = types.HexDecodeString("0x90b5ab205c6974c9ea841be688864633dc9ca8a357843eeacf2314649965fe22")
+)
+
+func Test_MultisigAccountId(t *testing.T) {
+	ms := client.Multisig{Signatories: [][]byte{charliePub, alicePub, bobPub}, Threshold: 2}
+	addr, err := ss58.EncodeWithNetwork(ms.AccountId(), 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr != "5DjYJStmdZ2rcqXbXGX7TW85JsrW6uG4y9MUcLq2BoPMpRA7" {
+		t.Fatalf("unexpected multisig address %s", addr)
+	}
+}
+
+func Test_MultisigCalls(t *testing.T) {
+	var deposit [16]byte
+	uint128.From64(5e12).PutBytes(deposit[:])
+	c := newMockNode(t, map[string]interface{}{
+		// opened at block 7 by extrinsic 1 of alice, approved by alice
+		"state_getStorage": "0x" + "07000000" + "01000000" + hex.EncodeToString(deposit[:]) +
+			hex.EncodeToString(alicePub) + "04" + hex.EncodeToString(alicePub),
+	})
+	ms := client.Multisig{Signatories: [][]byte{alicePub, bobPub, charliePub}, Threshold: 2}
+	ca, err := c.NewTransferCall(client.Transfer{Kind: client.TransferKeepAlive, Dest: "0x" + strings.Repeat("01", 32), Value: uint128.From64(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	callHash, err := client.CallHash(ca)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	op, err := c.GetMultisig(ms.AccountId(), callHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op.When != (client.Timepoint{Height: 7, Index: 1}) || !op.Deposit.Equals64(5e12) ||
+		op.Depositor != types.HexEncodeToString(alicePub) || len(op.Approvals) != 1 {
+		t.Fatalf("unexpected multisig operation %+v", op)
+	}
+
+	// bob approves with the call, the other signatories are sorted by account id
+	others := "08" + hex.EncodeToString(charliePub) + hex.EncodeToString(alicePub)
+	asMulti, err := c.NewAsMulti(ms, bobPub, ca, &op.When, client.Weight{RefTime: 1e9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	encodedCall, _ := types.Encode(ca)
+	callLen, _ := types.Encode(types.NewUCompactFromUInt(uint64(len(encodedCall))))
+	// threshold, others, Some(timepoint), opaque call, store_call, max_weight
+	want := "0200" + others + "01" + "07000000" + "01000000" + hex.EncodeToString(callLen) +
+		hex.EncodeToString(encodedCall) + "00" + "00ca9a3b00000000"
+	if got := hex.EncodeToString(asMulti.Args); got != want {
+		t.Fatalf("unexpected as_multi args\n got %s\nwant %s", got, want)
+	}
+
+	approve, err := c.NewApproveAsMulti(ms, charliePub, callHash, nil, client.Weight{RefTime: 1e9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(approve.Args, callHash[:]) || approve.Args[2+1+64] != 0 {
+		t.Fatalf("unexpected approve_as_multi args %x", approve.Args)
+	}
+
+	cancel, err := c.NewCancelAsMulti(ms, alicePub, callHash, op.When)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasSuffix(cancel.Args, append([]byte{7, 0, 0, 0, 1, 0, 0, 0}, callHash[:]...)) {
+		t.Fatalf("unexpected cancel_as_multi args %x", cancel.Args)
+	}
+
+	if _, err := c.NewCancelAsMulti(ms, bytes.Repeat([]byte{9}, 32), callHash, op.When); err == nil {
+		t.Fatal("expected an error for a non signatory")
+	}
+}
@@ -0,0 +1,134 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DataHighway-DHX/substrate-go/client"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"github.com/gorilla/websocket"
+)
+
+// rpcHandler computes the result of a JSON-RPC call from its params
+type rpcHandler func(params []json.RawMessage) (interface{}, error)
+
+var testBlockHash = "0x" + "ab" + "00000000000000000000000000000000000000000000000000000000000000"
+
+// mockResults overrides the default results of the mock node by method
+func mockResults(results map[string]interface{}) map[string]interface{} {
+	defaults := map[string]interface{}{
+		"state_getMetadata": types.MetadataV14Data,
+		"state_getRuntimeVersion": map[string]interface{}{
+			"specName": "substrate", "implName": "substrate", "authoringVersion": 1,
+			"specVersion": 100, "implVersion": 1, "transactionVersion": 1, "apis": []interface{}{},
+		},
+		"system_properties":      map[string]interface{}{"ss58Format": 42, "tokenDecimals": 12, "tokenSymbol": "UNIT"},
+		"chain_getBlockHash":     testBlockHash,
+		"chain_getFinalizedHead": testBlockHash,
+		"chain_getHeader": map[string]interface{}{
+			"parentHash": testBlockHash, "number": "0x64", "stateRoot": testBlockHash,
+			"extrinsicsRoot": testBlockHash, "digest": map[string]interface{}{"logs": []interface{}{}},
+		},
+		"state_getStorage": nil,
+	}
+	for k, v := range results {
+		defaults[k] = v
+	}
+	return defaults
+}
+
+// newMockNode starts an HTTP JSON-RPC server answering with results by method and returns a client connected to it
+func newMockNode(t *testing.T, results map[string]interface{}) *client.Client {
+	defaults := mockResults(results)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req mockRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(mockResponse(defaults, req))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := client.New(srv.URL, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+type mockRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+func mockResponse(results map[string]interface{}, req mockRequest) map[string]interface{} {
+	resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+	result, ok := results[req.Method]
+	if h, isHandler := result.(rpcHandler); isHandler {
+		var err error
+		result, err = h(req.Params)
+		if err != nil {
+			resp["error"] = map[string]interface{}{"code": 1010, "message": err.Error()}
+			ok = false
+		}
+	} else if !ok {
+		resp["error"] = map[string]interface{}{"code": -32601, "message": "Method not found: " + req.Method}
+	}
+	if ok {
+		resp["result"] = result
+	}
+	return resp
+}
+
+// watchHandler returns the statuses author_submitAndWatchExtrinsic streams for an extrinsic, the connection is closed after them when hangUp is set
+type watchHandler func(ext string) (statuses []interface{}, hangUp bool)
+
+// newMockWSNode is newMockNode over a websocket, extrinsics submitted with author_submitAndWatchExtrinsic are answered by watch
+func newMockWSNode(t *testing.T, results map[string]interface{}, watch watchHandler) *client.Client {
+	defaults := mockResults(results)
+	var upgrader websocket.Upgrader
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			var req mockRequest
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			if req.Method != "author_submitAndWatchExtrinsic" {
+				conn.WriteJSON(mockResponse(defaults, req))
+				continue
+			}
+			var ext string
+			json.Unmarshal(req.Params[0], &ext)
+			statuses, hangUp := watch(ext)
+			conn.WriteJSON(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": "watch"})
+			for _, st := range statuses {
+				conn.WriteJSON(map[string]interface{}{
+					"jsonrpc": "2.0", "method": "author_extrinsicUpdate",
+					"params": map[string]interface{}{"subscription": "watch", "result": st},
+				})
+			}
+			if hangUp {
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := client.New("ws"+strings.TrimPrefix(srv.URL, "http"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
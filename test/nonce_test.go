@@ -0,0 +1,138 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/DataHighway-DHX/substrate-go/client"
+	"github.com/DataHighway-DHX/substrate-go/signer"
+	"github.com/DataHighway-DHX/substrate-go/uint128"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+func Test_NonceManager(t *testing.T) {
+	alice, bob := []byte("alice"), []byte("bob")
+	fetches := 0
+	nm := client.NewNonceManager(func(accountId []byte) (uint64, error) {
+		fetches++
+		if string(accountId) == "bob" {
+			return 100, nil
+		}
+		return 10, nil
+	})
+
+	var mu sync.Mutex
+	seen := make(map[uint64]bool)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := nm.Next(alice)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if seen[n] {
+				t.Errorf("nonce %d handed out twice", n)
+			}
+			seen[n] = true
+		}()
+	}
+	wg.Wait()
+	for n := uint64(10); n < 60; n++ {
+		if !seen[n] {
+			t.Fatalf("nonce %d was skipped", n)
+		}
+	}
+	if n, _ := nm.Next(bob); n != 100 {
+		t.Fatalf("bob: got nonce %d", n)
+	}
+
+	// a failed submission leaves a gap that is filled first
+	nm.Failed(alice, 20, errors.New("connection reset"))
+	if n, _ := nm.Next(alice); n != 20 {
+		t.Fatalf("expected released nonce 20, got %d", n)
+	}
+	if n, _ := nm.Next(alice); n != 60 {
+		t.Fatalf("expected nonce 60, got %d", n)
+	}
+
+	// a stale rejection resyncs from the source
+	before := fetches
+	nm.Failed(alice, 60, errors.New("1010: Invalid Transaction: Transaction is outdated"))
+	if n, _ := nm.Next(alice); n != 10 || fetches != before+1 {
+		t.Fatalf("expected resync to nonce 10, got %d after %d fetches", n, fetches-before)
+	}
+}
+
+func Test_ConcurrentAuthorTransfer(t *testing.T) {
+	const n = 20
+	var versions int32
+	var mu sync.Mutex
+	var submitted []string
+	c := newMockNode(t, map[string]interface{}{
+		// the runtime is upgraded while the transfers are signed
+		"state_getRuntimeVersion": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			spec := 100
+			if atomic.AddInt32(&versions, 1) > n {
+				spec = 101
+			}
+			return map[string]interface{}{
+				"specName": "substrate", "implName": "substrate", "authoringVersion": 1,
+				"specVersion": spec, "implVersion": 1, "transactionVersion": 1, "apis": []interface{}{},
+			}, nil
+		}),
+		"author_submitExtrinsic": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			var ext string
+			json.Unmarshal(params[0], &ext)
+			mu.Lock()
+			defer mu.Unlock()
+			submitted = append(submitted, ext)
+			return testBlockHash, nil
+		}),
+	})
+	c.Nonces = client.NewNonceManager(func([]byte) (uint64, error) { return 5, nil })
+	from, _ := signer.FromSecret(signature.TestKeyringPairAlice.URI, signer.Sr25519)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.AuthorTransfer(from, client.Transfer{Dest: "0x" + strings.Repeat("01", 32), Value: uint128.From64(1)}, uint128.Zero)
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(submitted) != n {
+		t.Fatalf("submitted %d transfers, want %d", len(submitted), n)
+	}
+	seen := make(map[uint64]bool)
+	for _, h := range submitted {
+		var ext types.Extrinsic
+		if err := types.DecodeFromHex(h, &ext); err != nil {
+			t.Fatal(err)
+		}
+		nonce := ext.Signature.Nonce.Int64()
+		if seen[uint64(nonce)] {
+			t.Fatalf("nonce %d signed twice", nonce)
+		}
+		seen[uint64(nonce)] = true
+	}
+	for nonce := uint64(5); nonce < 5+n; nonce++ {
+		if !seen[nonce] {
+			t.Fatalf("nonce %d was skipped", nonce)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DataHighway-DHX/substrate-go/client"
+	"github.com/DataHighway-DHX/substrate-go/signer"
+	"github.com/DataHighway-DHX/substrate-go/uint128"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+func Test_SignUnsignedTx(t *testing.T) {
+	alice := signature.TestKeyringPairAlice
+	era, _, err := client.NewMortalEra(64, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eraHex, _ := types.EncodeToHex(era)
+	unsigned := &client.UnsignedTx{
+		Signer:             types.HexEncodeToString(alice.PublicKey),
+		Crypto:             "sr25519",
+		Call:               "0x0600ff8eaf04151687736326c9fea17e25fc5287613693c912909cb226aa4794f26a4804",
+		Era:                eraHex,
+		Nonce:              7,
+		Tip:                "0",
+		SpecVersion:        100,
+		TransactionVersion: 1,
+		GenesisHash:        "0x" + "11223344556677881122334455667788" + "11223344556677881122334455667788",
+		BlockHash:          "0x" + "99887766554433229988776655443322" + "99887766554433229988776655443322",
+	}
+	data, err := unsigned.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsigned, err = client.UnmarshalUnsignedTx(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from, err := signer.FromSecret(alice.URI, signer.Sr25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := client.SignUnsignedTx(unsigned, from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ext, err := signed.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ext.Signature.Nonce.Int64() != 7 || ext.Signature.Era != era {
+		t.Fatalf("unexpected signature fields %+v", ext.Signature)
+	}
+	payload, err := unsigned.SigningPayload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err := signature.Verify(payload, ext.Signature.Signature.AsSr25519[:], alice.URI)
+	if err != nil || !ok {
+		t.Fatalf("signature does not verify: %v", err)
+	}
+
+	other, _ := signer.FromSecret(alice.URI+"//1", signer.Sr25519)
+	if _, err := client.SignUnsignedTx(unsigned, other); err == nil {
+		t.Fatal("expected error for a secret of another account")
+	}
+}
+
+func Test_BuildUnsignedTransfer(t *testing.T) {
+	c := newMockNode(t, nil)
+	c.Nonces = client.NewNonceManager(func([]byte) (uint64, error) { return 3, nil })
+	from, err := signer.FromSecret(signature.TestKeyringPairAlice.URI, signer.Ecdsa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transfer := client.Transfer{Dest: "0x" + strings.Repeat("01", 32), Value: uint128.From64(1)}
+	unsigned, err := c.BuildUnsignedTransfer(signer.AccountId(from), signer.Ecdsa, transfer, uint128.Zero)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unsigned.Crypto != "ecdsa" || unsigned.Nonce != 3 {
+		t.Fatalf("unexpected unsigned tx %+v", unsigned)
+	}
+
+	payload, err := unsigned.SigningPayload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := from.Sign(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := client.AssembleSignedTx(unsigned, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ext, err := signed.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ext.Signature.Signature.IsEcdsa {
+		t.Fatalf("expected an ecdsa signature, got %+v", ext.Signature.Signature)
+	}
+
+	// the signature type is never guessed
+	unsigned.Crypto = ""
+	if _, err := client.AssembleSignedTx(unsigned, sig); err == nil {
+		t.Fatal("expected an error for an unsigned tx without crypto type")
+	}
+	if _, err := c.BuildUnsignedTransfer(signer.AccountId(from), signer.CryptoType(9), transfer, uint128.Zero); err == nil {
+		t.Fatal("expected an error for an unknown crypto type")
+	}
+}
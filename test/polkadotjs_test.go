@@ -0,0 +1,80 @@
+package test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/DataHighway-DHX/substrate-go/signer"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+func Test_PolkadotJSKeystore(t *testing.T) {
+	for _, crypto := range []signer.CryptoType{signer.Ed25519, signer.Sr25519, signer.Ecdsa} {
+		kr, err := signer.FromSecret("//Alice", crypto)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := signer.ExportPolkadotJS(kr, "pass", 42, "alice")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := signer.ImportPolkadotJS(data, "wrong"); !errors.Is(err, signer.ErrWrongPassword) {
+			t.Fatalf("%s: expected ErrWrongPassword, got %v", crypto, err)
+		}
+		imported, err := signer.ImportPolkadotJS(data, "pass")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if imported.CryptoType() != crypto || !bytes.Equal(imported.PublicKey(), kr.PublicKey()) {
+			t.Fatalf("%s: imported %s %x", crypto, imported.CryptoType(), imported.PublicKey())
+		}
+		sig, err := imported.Sign([]byte("payload"))
+		if err != nil || !kr.Verify([]byte("payload"), sig) {
+			t.Fatalf("%s: imported key signs differently: %v", crypto, err)
+		}
+	}
+}
+
+func Test_PolkadotJSFormat(t *testing.T) {
+	alice, _ := signer.FromSecret("//Alice", signer.Sr25519)
+	data, err := signer.ExportPolkadotJS(alice, "pass", 42, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var acc signer.PolkadotJSAccount
+	if err := json.Unmarshal(data, &acc); err != nil {
+		t.Fatal(err)
+	}
+	if acc.Address != "5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKutQY" || acc.Encoding.Version != "3" {
+		t.Fatalf("unexpected account %s version %s", acc.Address, acc.Encoding.Version)
+	}
+
+	// salt, scrypt N p r, nonce, then the sealed pkcs8 with the secret key polkadot-js uses for Alice
+	encoded, _ := base64.StdEncoding.DecodeString(acc.Encoded)
+	n, p, r := binary.LittleEndian.Uint32(encoded[32:]), binary.LittleEndian.Uint32(encoded[36:]), binary.LittleEndian.Uint32(encoded[40:])
+	key, err := scrypt.Key([]byte("pass"), encoded[:32], int(n), int(r), int(p), 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var nonce [24]byte
+	var box [32]byte
+	copy(nonce[:], encoded[44:])
+	copy(box[:], key)
+	plain, ok := secretbox.Open(nil, encoded[68:], &nonce, &box)
+	if !ok {
+		t.Fatal("can't open the encoded secret")
+	}
+	secret := types.HexEncodeToString(plain[16:80])
+	if secret != "0x98319d4ff8a9508c4bb0cf0b5a78d760a0b2082c02775e6e82370816fedfff48925a225d97aa00682d6a59b95b18780c10d7032336e88f3442b42361f4a66011" {
+		t.Fatalf("unexpected pkcs8 secret %s", secret)
+	}
+	if !bytes.Equal(plain[85:], alice.PublicKey()) {
+		t.Fatalf("unexpected pkcs8 public key %x", plain[85:])
+	}
+}
@@ -0,0 +1,83 @@
+package test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/DataHighway-DHX/substrate-go/client"
+	"github.com/DataHighway-DHX/substrate-go/signer"
+	"github.com/DataHighway-DHX/substrate-go/uint128"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+func Test_ProxyCalls(t *testing.T) {
+	var deposit [16]byte
+	uint128.From64(2e12).PutBytes(deposit[:])
+	var submitted string
+	c := newMockNode(t, map[string]interface{}{
+		// alice as Any proxy and bob as NonTransfer proxy with a delay of 10 blocks
+		"state_getStorage": "0x" + "08" + hex.EncodeToString(alicePub) + "00" + "00000000" +
+			hex.EncodeToString(bobPub) + "01" + "0a000000" + hex.EncodeToString(deposit[:]),
+		"author_submitExtrinsic": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			json.Unmarshal(params[0], &submitted)
+			return testBlockHash, nil
+		}),
+	})
+
+	proxies, err := c.GetProxies(charliePub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []client.ProxyDefinition{
+		{Delegate: types.HexEncodeToString(alicePub), ProxyType: "Any"},
+		{Delegate: types.HexEncodeToString(bobPub), ProxyType: "NonTransfer", Delay: 10},
+	}
+	if len(proxies.Proxies) != 2 || proxies.Proxies[0] != want[0] || proxies.Proxies[1] != want[1] || !proxies.Deposit.Equals64(2e12) {
+		t.Fatalf("unexpected proxies %+v", proxies)
+	}
+
+	add, err := c.NewAddProxy(bobPub, "NonTransfer", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(add.Args); got != hex.EncodeToString(bobPub)+"01"+"0a000000" {
+		t.Fatalf("unexpected add_proxy args %s", got)
+	}
+	if _, err := c.NewRemoveProxy(bobPub, "NoSuchType", 0); err == nil {
+		t.Fatal("expected an error for an unknown proxy type")
+	}
+
+	ca, err := c.NewTransferCall(client.Transfer{Kind: client.TransferKeepAlive, Dest: "0x" + strings.Repeat("01", 32), Value: uint128.From64(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	encodedCall, _ := types.Encode(ca)
+	announced, err := c.NewProxyAnnounced(bobPub, charliePub, "NonTransfer", ca)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantArgs := hex.EncodeToString(bobPub) + hex.EncodeToString(charliePub) + "0101" + hex.EncodeToString(encodedCall)
+	if got := hex.EncodeToString(announced.Args); got != wantArgs {
+		t.Fatalf("unexpected proxy_announced args %s", got)
+	}
+
+	// alice transfers funds of charlie
+	from, _ := signer.FromSecret(signature.TestKeyringPairAlice.URI, signer.Sr25519)
+	_, err = c.AuthorProxyTransfer(from, charliePub, client.Transfer{Kind: client.TransferKeepAlive, Dest: "0x" + strings.Repeat("01", 32), Value: uint128.From64(1)}, uint128.Zero)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ext types.Extrinsic
+	if err := types.DecodeFromHex(submitted, &ext); err != nil {
+		t.Fatal(err)
+	}
+	proxy, _ := c.NewProxy(charliePub, "", ca)
+	if !bytes.Equal(ext.Signature.Signer.AsID[:], alicePub) || ext.Method.CallIndex != proxy.CallIndex ||
+		!bytes.Equal(ext.Method.Args, proxy.Args) {
+		t.Fatalf("unexpected proxied transfer %+v", ext.Method)
+	}
+}
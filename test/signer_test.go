@@ -0,0 +1,92 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DataHighway-DHX/substrate-go/client"
+	"github.com/DataHighway-DHX/substrate-go/signer"
+	"github.com/DataHighway-DHX/substrate-go/uint128"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+func Test_Keystore(t *testing.T) {
+	alice := signature.TestKeyringPairAlice
+	ks, err := signer.NewKeystore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := ks.Import(alice.URI, signer.Sr25519, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(pub, alice.PublicKey) {
+		t.Fatalf("imported %x, want %x", pub, alice.PublicKey)
+	}
+	accounts, err := ks.Accounts()
+	if err != nil || len(accounts) != 1 || !bytes.Equal(accounts[0], pub) {
+		t.Fatalf("unexpected accounts %x %v", accounts, err)
+	}
+	if _, err := ks.Unlock(pub, "wrong"); !errors.Is(err, signer.ErrWrongPassword) {
+		t.Fatalf("expected ErrWrongPassword, got %v", err)
+	}
+	kr, err := ks.Unlock(pub, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := newMockNode(t, nil)
+	ca, err := client.NewCall(c.Meta, "Balances.transfer", types.NewMultiAddressFromAccountID(bytes.Repeat([]byte{1}, 32)), types.NewUCompactFromUInt(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ext, err := c.SignCall(kr, ca, uint128.Zero)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ext.IsSigned() || !bytes.Equal(ext.Signature.Signer.AsID[:], alice.PublicKey) {
+		t.Fatalf("unexpected signature %+v", ext.Signature)
+	}
+}
+
+func Test_RemoteSigner(t *testing.T) {
+	alice, err := signer.FromSecret(signature.TestKeyringPairAlice.URI, signer.Sr25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(signer.NewServer(alice))
+	defer srv.Close()
+
+	remote := signer.NewRemote(srv.URL, alice.PublicKey(), signer.Sr25519)
+	unsigned := &client.UnsignedTx{
+		Signer:             types.HexEncodeToString(alice.PublicKey()),
+		Crypto:             "sr25519",
+		Call:               "0x0600ff8eaf04151687736326c9fea17e25fc5287613693c912909cb226aa4794f26a4804",
+		Era:                "0x00",
+		Tip:                "0",
+		SpecVersion:        100,
+		TransactionVersion: 1,
+		GenesisHash:        testBlockHash,
+		BlockHash:          testBlockHash,
+	}
+	signed, err := client.SignUnsignedTx(unsigned, remote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ext, err := signed.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, _ := unsigned.SigningPayload()
+	if !alice.Verify(payload, ext.Signature.Signature.AsSr25519[:]) {
+		t.Fatal("remote signature does not verify")
+	}
+
+	unknown := signer.NewRemote(srv.URL, signature.TestKeyringPairAlice.PublicKey[:31], signer.Sr25519)
+	if _, err := unknown.Sign(payload); err == nil {
+		t.Fatal("expected error for a key the server does not hold")
+	}
+}
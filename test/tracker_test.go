@@ -0,0 +1,323 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DataHighway-DHX/substrate-go/client"
+	"github.com/DataHighway-DHX/substrate-go/signer"
+	"github.com/DataHighway-DHX/substrate-go/uint128"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+const eventsKey = "0x26aa394eea5630e07c48ae0c9558cef780d41e5e16056765bc8461851072c9d7"
+
+// mockChain is a chain whose head, account nonce, blocks and pool are set by the test
+type mockChain struct {
+	mu        sync.Mutex
+	head      int64
+	nonce     uint32
+	blocks    map[int64][]string
+	pool      []string
+	submitted []string
+	// the finalized head is lag blocks behind the head, with finalizedNonce as account nonce
+	lag            int64
+	finalizedNonce uint32
+	poolErr        error
+}
+
+// height of a block hash param, the head when there is none
+func (m *mockChain) height(params []json.RawMessage) int64 {
+	if len(params) == 0 {
+		return m.head
+	}
+	var hash string
+	json.Unmarshal(params[0], &hash)
+	var height int64
+	fmt.Sscanf(hash, "0x%x", &height)
+	return height
+}
+
+func (m *mockChain) blockHash(height int64) string {
+	return fmt.Sprintf("0x%064x", height)
+}
+
+func (m *mockChain) node(t *testing.T) *client.Client {
+	header := func(height int64) map[string]interface{} {
+		return map[string]interface{}{
+			"parentHash": testBlockHash, "number": fmt.Sprintf("0x%x", height), "stateRoot": testBlockHash,
+			"extrinsicsRoot": testBlockHash, "digest": map[string]interface{}{"logs": []interface{}{}},
+		}
+	}
+	return newMockNode(t, map[string]interface{}{
+		"chain_getHeader": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			return header(m.height(params)), nil
+		}),
+		"chain_getFinalizedHead": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			return m.blockHash(m.head - m.lag), nil
+		}),
+		"chain_getBlockHash": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			height := m.head
+			if len(params) > 0 {
+				json.Unmarshal(params[0], &height)
+			}
+			return m.blockHash(height), nil
+		}),
+		"chain_getBlock": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			height := m.height(params)
+			exts := m.blocks[height]
+			if exts == nil {
+				exts = []string{}
+			}
+			return map[string]interface{}{
+				"block": map[string]interface{}{"header": header(height), "extrinsics": exts}, "justifications": nil,
+			}, nil
+		}),
+		"state_getStorage": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			var key string
+			json.Unmarshal(params[0], &key)
+			if key == eventsKey {
+				return "0x00", nil
+			}
+			n := m.nonce
+			if len(params) > 1 && m.lag > 0 && m.height(params[1:]) <= m.head-m.lag {
+				n = m.finalizedNonce
+			}
+			nonce, _ := types.EncodeToHex(types.NewU32(n))
+			return nonce + "00000000" + "01000000" + "00000000" + strings.Repeat("00", 64), nil
+		}),
+		"author_submitExtrinsic": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			var ext string
+			json.Unmarshal(params[0], &ext)
+			m.submitted = append(m.submitted, ext)
+			m.pool = []string{ext}
+			return testBlockHash, nil
+		}),
+		"author_pendingExtrinsics": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			if m.poolErr != nil {
+				return nil, m.poolErr
+			}
+			return append([]string{}, m.pool...), nil
+		}),
+	})
+}
+
+func decodeSubmitted(t *testing.T, hex string) types.Extrinsic {
+	var ext types.Extrinsic
+	if err := types.DecodeFromHex(hex, &ext); err != nil {
+		t.Fatal(err)
+	}
+	return ext
+}
+
+func Test_TrackerResubmit(t *testing.T) {
+	chain := &mockChain{head: 100, nonce: 7, blocks: make(map[int64][]string)}
+	c := chain.node(t)
+	from, _ := signer.FromSecret(signature.TestKeyringPairAlice.URI, signer.Sr25519)
+	ca, err := c.NewTransferCall(client.Transfer{Dest: "0x" + strings.Repeat("01", 32), Value: uint128.From64(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "txs.json")
+	tr, err := c.NewTracker(client.NewFileTxStore(path), client.RetryPolicy{AfterBlocks: 2, TipBump: uint128.From64(5)}, from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := tr.Submit(from, ca, uint128.Zero)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Nonce != 7 || tx.Attempts != 1 || tx.ValidUntil != 164 {
+		t.Fatalf("unexpected tracked tx %+v", tx)
+	}
+
+	// still in the pool within AfterBlocks
+	chain.head = 101
+	if _, err := tr.Check(); err != nil {
+		t.Fatal(err)
+	}
+	if len(chain.submitted) != 1 {
+		t.Fatalf("resubmitted too early")
+	}
+
+	// not included within AfterBlocks, signed again with the same nonce and a bumped tip
+	chain.head = 102
+	if _, err := tr.Check(); err != nil {
+		t.Fatal(err)
+	}
+	tx, _ = tr.Get(tx.Id)
+	if len(chain.submitted) != 2 || len(tx.Hashes) != 2 || tx.Tip != "5" {
+		t.Fatalf("expected a resubmission, got %d submissions %+v", len(chain.submitted), tx)
+	}
+	ext := decodeSubmitted(t, chain.submitted[1])
+	if ext.Signature.Nonce.Int64() != 7 || ext.Signature.Tip.Int64() != 5 {
+		t.Fatalf("resubmitted with nonce %d tip %d", ext.Signature.Nonce.Int64(), ext.Signature.Tip.Int64())
+	}
+
+	// a restarted tracker picks the transaction up from the store
+	tr, err = c.NewTracker(client.NewFileTxStore(path), client.RetryPolicy{AfterBlocks: 2, TipBump: uint128.From64(5)}, from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tr.Pending()) != 1 {
+		t.Fatalf("expected one pending transaction after reload, got %d", len(tr.Pending()))
+	}
+
+	chain.head, chain.nonce = 103, 8
+	chain.blocks[103] = []string{chain.submitted[1]}
+	done, err := tr.Check()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != 1 || done[0].Status != client.TrackIncluded || done[0].Height != 103 || !done[0].Success ||
+		done[0].TxHash != done[0].Hashes[1] {
+		t.Fatalf("expected inclusion, got %+v", done)
+	}
+	if len(chain.submitted) != 2 {
+		t.Fatal("included transaction was resubmitted")
+	}
+}
+
+func Test_TrackerNonceConsumed(t *testing.T) {
+	chain := &mockChain{head: 100, nonce: 7, blocks: make(map[int64][]string)}
+	c := chain.node(t)
+	from, _ := signer.FromSecret(signature.TestKeyringPairAlice.URI, signer.Sr25519)
+	ca, err := c.NewTransferCall(client.Transfer{Dest: "0x" + strings.Repeat("01", 32), Value: uint128.From64(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr, err := c.NewTracker(client.NewFileTxStore(filepath.Join(t.TempDir(), "txs.json")), client.RetryPolicy{AfterBlocks: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tr.Submit(from, ca, uint128.Zero); err != nil {
+		t.Fatal(err)
+	}
+
+	// another transaction of the signer used nonce 7 and the pool dropped ours
+	chain.head, chain.nonce, chain.pool = 105, 8, nil
+	done, err := tr.Check()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != 1 || done[0].Status != client.TrackNonceConsumed {
+		t.Fatalf("expected nonce_consumed, got %+v", done)
+	}
+	if len(chain.submitted) != 1 {
+		t.Fatal("transaction with a consumed nonce was resubmitted")
+	}
+	if done, _ := tr.Check(); len(done) != 0 || len(chain.submitted) != 1 {
+		t.Fatal("finished transaction was checked again")
+	}
+}
+
+func Test_TrackerWaitsForFinality(t *testing.T) {
+	chain := &mockChain{head: 100, nonce: 7, finalizedNonce: 7, lag: 2, blocks: make(map[int64][]string)}
+	c := chain.node(t)
+	from, _ := signer.FromSecret(signature.TestKeyringPairAlice.URI, signer.Sr25519)
+	ca, err := c.NewTransferCall(client.Transfer{Dest: "0x" + strings.Repeat("01", 32), Value: uint128.From64(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr, err := c.NewTracker(client.NewFileTxStore(filepath.Join(t.TempDir(), "txs.json")), client.RetryPolicy{}, from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := tr.Submit(from, ca, uint128.Zero)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// in the best block, which is not finalized yet
+	chain.head, chain.nonce, chain.pool = 101, 8, nil
+	chain.blocks[101] = []string{chain.submitted[0]}
+	done, err := tr.Check()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx, _ = tr.Get(tx.Id); len(done) != 0 || tx.Status != client.TrackPending || len(chain.submitted) != 1 {
+		t.Fatalf("expected to wait for finality, got %+v after %d submissions", tx, len(chain.submitted))
+	}
+
+	// a reorg drops the block, the transaction is sent again
+	chain.head, chain.nonce = 102, 7
+	delete(chain.blocks, 101)
+	if _, err := tr.Check(); err != nil {
+		t.Fatal(err)
+	}
+	if len(chain.submitted) != 2 {
+		t.Fatalf("expected a resubmission after the reorg, got %d submissions", len(chain.submitted))
+	}
+
+	// included again and finalized
+	chain.head, chain.nonce, chain.lag = 104, 8, 0
+	chain.blocks[103] = []string{chain.submitted[1]}
+	done, err = tr.Check()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != 1 || done[0].Status != client.TrackIncluded || done[0].Height != 103 {
+		t.Fatalf("expected inclusion at 103, got %+v", done)
+	}
+}
+
+func Test_TrackerRunErrors(t *testing.T) {
+	chain := &mockChain{head: 100, nonce: 7, blocks: make(map[int64][]string)}
+	c := chain.node(t)
+	from, _ := signer.FromSecret(signature.TestKeyringPairAlice.URI, signer.Sr25519)
+	ca, err := c.NewTransferCall(client.Transfer{Dest: "0x" + strings.Repeat("01", 32), Value: uint128.From64(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr, err := c.NewTracker(client.NewFileTxStore(filepath.Join(t.TempDir(), "txs.json")), client.RetryPolicy{}, from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tr.Submit(from, ca, uint128.Zero); err != nil {
+		t.Fatal(err)
+	}
+	chain.mu.Lock()
+	chain.poolErr = errors.New("pool unavailable")
+	chain.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go tr.Run(ctx, 10*time.Millisecond, nil, func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+		cancel()
+	})
+	select {
+	case err := <-errs:
+		if !strings.Contains(err.Error(), "pool unavailable") {
+			t.Fatalf("unexpected error %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not report the failed pass")
+	}
+}
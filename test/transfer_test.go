@@ -0,0 +1,98 @@
+package test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/DataHighway-DHX/substrate-go/client"
+	"github.com/DataHighway-DHX/substrate-go/uint128"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+)
+
+// renameCall renames a call of the test metadata to mimic another runtime, it is removed when to is empty
+func renameCall(t *testing.T, meta *types.Metadata, call, to string) {
+	s := strings.Split(call, ".")
+	for _, mod := range meta.AsMetadataV14.Pallets {
+		if string(mod.Name) != s[0] || !mod.HasCalls {
+			continue
+		}
+		ct := meta.AsMetadataV14.EfficientLookup[mod.Calls.Type.Int64()]
+		variants := ct.Def.Variant.Variants
+		for i := range variants {
+			if string(variants[i].Name) != s[1] {
+				continue
+			}
+			if to == "" {
+				ct.Def.Variant.Variants = append(variants[:i:i], variants[i+1:]...)
+			} else {
+				variants[i].Name = types.Text(to)
+			}
+			return
+		}
+	}
+	t.Fatalf("call %s not found", call)
+}
+
+func Test_NewTransferCallKinds(t *testing.T) {
+	c := newMockNode(t, nil)
+	oldMeta := testMetadata(t)
+	// newer runtimes renamed Balances.transfer to transfer_allow_death
+	newMeta := testMetadata(t)
+	renameCall(t, newMeta, "Balances.transfer", "transfer_allow_death")
+
+	dest := "0x" + strings.Repeat("01", 32)
+	source := "0x" + strings.Repeat("02", 32)
+	cases := []struct {
+		transfer client.Transfer
+		call     string // expected call, newCall instead in the new metadata when set
+		newCall  string
+		args     string // expected args after the destination
+	}{
+		{client.Transfer{Kind: client.TransferAllowDeath, Dest: dest, Value: uint128.From64(5)}, "Balances.transfer", "Balances.transfer_allow_death", "14"},
+		{client.Transfer{Kind: client.TransferKeepAlive, Dest: dest, Value: uint128.From64(5)}, "Balances.transfer_keep_alive", "", "14"},
+		{client.Transfer{Kind: client.TransferAll, Dest: dest, KeepAlive: true}, "Balances.transfer_all", "", "01"},
+		{client.Transfer{Kind: client.TransferAll, Dest: dest}, "Balances.transfer_all", "", "00"},
+		{client.Transfer{Kind: client.ForceTransfer, Source: source, Dest: dest, Value: uint128.From64(5)}, "Balances.force_transfer", "", "14"},
+	}
+	for _, meta := range []*types.Metadata{oldMeta, newMeta} {
+		c.Meta = meta
+		for i, tc := range cases {
+			name := tc.call
+			if meta == newMeta && tc.newCall != "" {
+				name = tc.newCall
+			}
+			ca, err := c.NewTransferCall(tc.transfer)
+			if err != nil {
+				t.Fatalf("case %d: %v", i, err)
+			}
+			want, err := meta.FindCallIndex(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ca.CallIndex != want {
+				t.Fatalf("case %d: expected %s, got call index %+v", i, name, ca.CallIndex)
+			}
+			args := types.HexEncodeToString(ca.Args)
+			destArg := "00" + strings.Repeat("01", 32)
+			if tc.transfer.Kind == client.ForceTransfer {
+				destArg = "00" + strings.Repeat("02", 32) + destArg
+			}
+			if args != "0x"+destArg+tc.args {
+				t.Fatalf("case %d: unexpected %s args %s", i, name, args)
+			}
+		}
+	}
+
+	// a runtime without the call
+	c.Meta = testMetadata(t)
+	renameCall(t, c.Meta, "Balances.transfer_keep_alive", "")
+	_, err := c.NewTransferCall(client.Transfer{Kind: client.TransferKeepAlive, Dest: dest, Value: uint128.From64(5)})
+	if !errors.Is(err, client.ErrCallNotFound) {
+		t.Fatalf("expected ErrCallNotFound, got %v", err)
+	}
+	_, err = c.NewTransferCall(client.Transfer{Kind: client.TransferKind(99), Dest: dest})
+	if err == nil || errors.Is(err, client.ErrCallNotFound) {
+		t.Fatalf("expected an unknown kind error, got %v", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/DataHighway-DHX/substrate-go/client"
+	"github.com/DataHighway-DHX/substrate-go/uint128"
 	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
 	"github.com/vedhavyas/go-subkey"
 )
@@ -18,8 +19,8 @@ func Test_Tx2(t *testing.T) {
 
 	senderSecret := ""
 	recieverAccId := ""
-	amount := uint64(10000000000)
-	tip := uint64(0)
+	amount := uint128.From64(10000000000)
+	tip := uint128.Zero
 
 	fromKp, err := signature.KeyringPairFromSecret(
 		senderSecret,
@@ -27,7 +28,7 @@ func Test_Tx2(t *testing.T) {
 
 	from, err := subkey.SS58Address(fromKp.PublicKey[:], c.NetId)
 
-	fmt.Printf("from : %s to %s amount %d", from, recieverAccId, amount)
+	fmt.Printf("from : %s to %s amount %s", from, recieverAccId, amount)
 
 	txHash, err := c.AuthorTransferAsset(senderSecret, recieverAccId, amount, tip)
 	if err != nil {
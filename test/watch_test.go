@@ -0,0 +1,225 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DataHighway-DHX/substrate-go/client"
+	"github.com/DataHighway-DHX/substrate-go/signer"
+	"github.com/DataHighway-DHX/substrate-go/uint128"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/v4/types"
+	"golang.org/x/crypto/blake2b"
+)
+
+var (
+	inBlockHash   = fmt.Sprintf("0x%064x", 100)
+	finalizedHash = fmt.Sprintf("0x%064x", 101)
+	usurperHash   = fmt.Sprintf("0x%064x", 102)
+)
+
+// watchNode is a websocket mock node including every watched extrinsic in a block, with events as its System.Events
+type watchNode struct {
+	mu        sync.Mutex
+	events    string
+	included  string
+	unwatched bool
+	statuses  []interface{}
+	hangUp    bool
+	client    *client.Client
+}
+
+func newWatchNode(t *testing.T, statuses []interface{}, hangUp bool) *watchNode {
+	n := &watchNode{events: "0x00", statuses: statuses, hangUp: hangUp}
+	n.client = newMockWSNode(t, map[string]interface{}{
+		"chain_getBlock": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			n.mu.Lock()
+			defer n.mu.Unlock()
+			return map[string]interface{}{"block": map[string]interface{}{
+				"header": map[string]interface{}{
+					"parentHash": testBlockHash, "number": "0x64", "stateRoot": testBlockHash,
+					"extrinsicsRoot": testBlockHash, "digest": map[string]interface{}{"logs": []interface{}{}},
+				},
+				"extrinsics": []string{n.included},
+			}, "justifications": nil}, nil
+		}),
+		"state_getStorage": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			n.mu.Lock()
+			defer n.mu.Unlock()
+			return n.events, nil
+		}),
+		"author_unwatchExtrinsic": rpcHandler(func(params []json.RawMessage) (interface{}, error) {
+			n.mu.Lock()
+			defer n.mu.Unlock()
+			n.unwatched = true
+			return true, nil
+		}),
+	}, func(ext string) ([]interface{}, bool) {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		n.included = ext
+		return n.statuses, n.hangUp
+	})
+	n.client.Nonces = client.NewNonceManager(func([]byte) (uint64, error) { return 0, nil })
+	return n
+}
+
+func (n *watchNode) transfer(t *testing.T) types.Extrinsic {
+	from, _ := signer.FromSecret(signature.TestKeyringPairAlice.URI, signer.Sr25519)
+	ext, err := n.client.SignTransfer(from, client.Transfer{Dest: "0x" + strings.Repeat("01", 32), Value: uint128.From64(1)}, uint128.Zero)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ext
+}
+
+func (n *watchNode) isUnwatched() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.unwatched
+}
+
+func Test_TxWatchStatus(t *testing.T) {
+	pending := []interface{}{
+		"future", "ready", map[string]interface{}{"broadcast": []string{"peer1", "peer2"}},
+		map[string]interface{}{"inBlock": inBlockHash}, map[string]interface{}{"retracted": inBlockHash},
+	}
+	for _, tt := range []struct {
+		final interface{}
+		want  client.TxStatus
+		err   error
+	}{
+		{map[string]interface{}{"finalized": finalizedHash}, client.TxStatus{Status: "Finalized", BlockHash: types.NewHash(types.MustHexDecodeString(finalizedHash))}, nil},
+		{map[string]interface{}{"finalityTimeout": inBlockHash}, client.TxStatus{Status: "FinalityTimeout", BlockHash: types.NewHash(types.MustHexDecodeString(inBlockHash))}, client.ErrTxFinalityTimeout},
+		{map[string]interface{}{"usurped": usurperHash}, client.TxStatus{Status: "Usurped", Usurper: types.NewHash(types.MustHexDecodeString(usurperHash))}, client.ErrTxUsurped},
+		{"dropped", client.TxStatus{Status: "Dropped"}, client.ErrTxDropped},
+		{"invalid", client.TxStatus{Status: "Invalid"}, client.ErrTxInvalid},
+	} {
+		n := newWatchNode(t, append(append([]interface{}{}, pending...), tt.final), false)
+		w, err := n.client.SubmitAndWatch(n.transfer(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		var last client.TxStatus
+		for st := range w.Status() {
+			if st.Final() != (len(got) == len(pending)) {
+				t.Fatalf("%s: Final is %v", st.Status, st.Final())
+			}
+			switch st.Status {
+			case "Broadcast":
+				if strings.Join(st.Peers, ",") != "peer1,peer2" {
+					t.Fatalf("unexpected peers %v", st.Peers)
+				}
+			case "InBlock", "Retracted":
+				if st.BlockHash.Hex() != inBlockHash {
+					t.Fatalf("unexpected %s block %s", st.Status, st.BlockHash.Hex())
+				}
+			}
+			if st.Err() != nil && !st.Final() {
+				t.Fatalf("%s reports error %v", st.Status, st.Err())
+			}
+			got = append(got, st.Status)
+			last = st
+		}
+		if strings.Join(got, ",") != "Future,Ready,Broadcast,InBlock,Retracted,"+tt.want.Status {
+			t.Fatalf("unexpected statuses %v", got)
+		}
+		if last.Status != tt.want.Status || last.BlockHash != tt.want.BlockHash || last.Usurper != tt.want.Usurper {
+			t.Fatalf("unexpected final status %+v, want %+v", last, tt.want)
+		}
+		if err := last.Err(); !errors.Is(err, tt.err) || (tt.err == nil) != (err == nil) {
+			t.Fatalf("%s: unexpected error %v", last.Status, err)
+		}
+	}
+}
+
+func Test_Wait(t *testing.T) {
+	inBlock := map[string]interface{}{"inBlock": inBlockHash}
+	finalized := map[string]interface{}{"finalized": finalizedHash}
+	for _, tt := range []struct {
+		until    client.WaitUntil
+		statuses []interface{}
+		want     string
+	}{
+		{client.UntilInBlock, []interface{}{"ready", inBlock, finalized}, "InBlock"},
+		{client.UntilFinalized, []interface{}{"ready", inBlock, finalized}, "Finalized"},
+		// a finalized status ends the wait for inclusion as well
+		{client.UntilInBlock, []interface{}{"ready", finalized}, "Finalized"},
+	} {
+		n := newWatchNode(t, tt.statuses, false)
+		ext := n.transfer(t)
+		res, err := n.client.SubmitAndWait(context.Background(), ext, tt.until)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Status.Status != tt.want || res.BlockHash != res.Status.BlockHash || res.Height != 100 ||
+			res.ExtrinsicIndex != 0 || !res.Success || res.Error != "" {
+			t.Fatalf("unexpected result %+v", res)
+		}
+		extHex, _ := types.EncodeToHex(ext)
+		if txid := blake2b.Sum256(types.MustHexDecodeString(extHex)); res.TxHash != types.Hash(txid) {
+			t.Fatalf("unexpected tx hash %s", res.TxHash.Hex())
+		}
+	}
+}
+
+func Test_WaitErrors(t *testing.T) {
+	for _, tt := range []struct {
+		final interface{}
+		err   error
+	}{
+		{"invalid", client.ErrTxInvalid},
+		{"dropped", client.ErrTxDropped},
+		{map[string]interface{}{"usurped": usurperHash}, client.ErrTxUsurped},
+		{map[string]interface{}{"finalityTimeout": inBlockHash}, client.ErrTxFinalityTimeout},
+	} {
+		n := newWatchNode(t, []interface{}{"ready", tt.final}, false)
+		_, err := n.client.SubmitAndWait(context.Background(), n.transfer(t), client.UntilFinalized)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("expected %v, got %v", tt.err, err)
+		}
+	}
+
+	// the stream ends before a final status
+	n := newWatchNode(t, []interface{}{"ready", map[string]interface{}{"inBlock": inBlockHash}}, true)
+	_, err := n.client.SubmitAndWait(context.Background(), n.transfer(t), client.UntilFinalized)
+	if err == nil || !strings.Contains(err.Error(), "transaction status subscription") {
+		t.Fatalf("expected a subscription error, got %v", err)
+	}
+}
+
+func Test_WaitCancel(t *testing.T) {
+	n := newWatchNode(t, []interface{}{"ready"}, false)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := n.client.SubmitAndWait(ctx, n.transfer(t), client.UntilInBlock)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the context error, got %v", err)
+	}
+	for i := 0; i < 50 && !n.isUnwatched(); i++ {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if !n.isUnwatched() {
+		t.Fatal("the watch was not unsubscribed")
+	}
+}
+
+func Test_WaitFailedExtrinsic(t *testing.T) {
+	n := newWatchNode(t, []interface{}{map[string]interface{}{"inBlock": inBlockHash}}, false)
+	// ApplyExtrinsic(0), System.ExtrinsicFailed(Module {index: 6, error: 2}, DispatchInfo)
+	n.events = "0x04" + "0000000000" + "0001" + "030602" + "1027000000000000" + "00" + "00" + "00"
+	res, err := n.client.SubmitAndWait(context.Background(), n.transfer(t), client.UntilInBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Success || res.Error != "Balances.InsufficientBalance" || len(res.Events) != 1 || !res.Events[0].Is("System", "ExtrinsicFailed") {
+		t.Fatalf("unexpected result %+v", res)
+	}
+}
@@ -2,6 +2,8 @@ package uint128
 
 import (
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"math/big"
 	"math/bits"
 )
@@ -276,3 +278,36 @@ func FromBig(i *big.Int) (u Uint128) {
 	u.hi = new(big.Int).Rsh(i, 64).Uint64()
 	return u
 }
+
+// FromBigChecked converts i to a Uint128 value, returning an error instead of
+// panicking if i is negative or overflows 128 bits.
+func FromBigChecked(i *big.Int) (Uint128, error) {
+	if i == nil {
+		return Zero, errors.New("value is nil")
+	} else if i.Sign() < 0 {
+		return Zero, errors.New("value cannot be negative")
+	} else if i.BitLen() > 128 {
+		return Zero, errors.New("value overflows Uint128")
+	}
+	return FromBig(i), nil
+}
+
+// FromString parses s as a base-10 Uint128 value.
+func FromString(s string) (Uint128, error) {
+	i, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return Zero, fmt.Errorf("invalid Uint128 %q", s)
+	}
+	return FromBigChecked(i)
+}
+
+// MarshalText implements encoding.TextMarshaler, u is written in base 10.
+func (u Uint128) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (u *Uint128) UnmarshalText(b []byte) (err error) {
+	*u, err = FromString(string(b))
+	return err
+}